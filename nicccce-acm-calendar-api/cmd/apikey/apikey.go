@@ -0,0 +1,93 @@
+package apikey
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"nicccce-acm-calendar-api/config"
+	"nicccce-acm-calendar-api/internal/global/apikey"
+	"nicccce-acm-calendar-api/internal/global/cache"
+	"nicccce-acm-calendar-api/internal/global/database"
+	"nicccce-acm-calendar-api/internal/global/logger"
+	"nicccce-acm-calendar-api/internal/global/redis"
+	"nicccce-acm-calendar-api/internal/model"
+	"nicccce-acm-calendar-api/tools"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const usage = `Usage: main apikey <command>
+
+Commands:
+  create <name> [scope...]    创建 API Key 并输出 Key 原文，默认授权范围为 contests:read
+  list                        查看所有 API Key
+  revoke <id>                 吊销 API Key
+
+Scopes: `
+
+var log *slog.Logger
+
+// Run 执行 apikey 子命令，args 为子命令之后的参数
+// 用于在没有管理员 Token 时创建第一个具有 admin 授权范围的 API Key
+func Run(args []string) {
+	config.Init()
+	log = logger.New("APIKey")
+
+	database.Open()
+	defer database.Close()
+	// 吊销时需通知运行中的实例清除缓存的查询结果
+	redis.Init()
+	defer redis.Close()
+	cache.Init()
+	defer cache.Close()
+
+	if len(args) == 0 {
+		exit("missing command")
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "create":
+		if len(args) < 2 || strings.TrimSpace(args[1]) == "" {
+			exit("missing name")
+		}
+		key := model.APIKey{Name: args[1]}
+		raw, err := apikey.Create(ctx, &key, args[2:])
+		tools.PanicOnErr(err)
+		log.Info(fmt.Sprintf("Created API key %d %s", key.ID, key.Name), "scopes", key.Scopes)
+		// Key 原文只输出这一次
+		fmt.Println(raw)
+	case "list":
+		var keys []model.APIKey
+		tools.PanicOnErr(database.DB.Order("id ASC").Find(&keys).Error)
+		for _, k := range keys {
+			state := "active"
+			if !k.ActiveAt(time.Now()) {
+				state = "inactive"
+			}
+			fmt.Printf("%-6d %-16s %-30s %-30s %s\n", k.ID, k.Prefix, k.Name, k.Scopes, state)
+		}
+	case "revoke":
+		if len(args) < 2 {
+			exit("missing id")
+		}
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			exit(fmt.Sprintf("invalid id: %s", args[1]))
+		}
+		var key model.APIKey
+		tools.PanicOnErr(database.DB.First(&key, id).Error)
+		tools.PanicOnErr(apikey.Revoke(ctx, &key))
+		log.Info(fmt.Sprintf("Revoked API key %d %s", key.ID, key.Name))
+	default:
+		exit(fmt.Sprintf("unknown command: %s", args[0]))
+	}
+}
+
+func exit(msg string) {
+	fmt.Fprintln(os.Stderr, msg)
+	fmt.Fprintln(os.Stderr, usage+strings.Join(apikey.Scopes, ", "))
+	os.Exit(2)
+}
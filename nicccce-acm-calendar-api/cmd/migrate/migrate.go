@@ -0,0 +1,79 @@
+package migrate
+
+import (
+	"fmt"
+	"log/slog"
+	"nicccce-acm-calendar-api/config"
+	"nicccce-acm-calendar-api/internal/global/database"
+	"nicccce-acm-calendar-api/internal/global/logger"
+	"nicccce-acm-calendar-api/tools"
+	"os"
+	"strconv"
+)
+
+const usage = `Usage: main migrate <command>
+
+Commands:
+  up          执行所有未执行的迁移（默认）
+  down [n]    回滚最近执行的 n 个迁移，默认 1
+  status      查看所有迁移的执行状态`
+
+var log *slog.Logger
+
+// Run 执行 migrate 子命令，args 为子命令之后的参数
+func Run(args []string) {
+	config.Init()
+	log = logger.New("Migrate")
+
+	database.Open()
+	defer database.Close()
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		ran, err := database.Migrate(database.DB)
+		for _, m := range ran {
+			log.Info(fmt.Sprintf("Applied migration %d_%s", m.Version, m.Name))
+		}
+		tools.PanicOnErr(err)
+		if len(ran) == 0 {
+			log.Info("Database is up to date")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				exit(fmt.Sprintf("invalid step count: %s", args[1]))
+			}
+			steps = n
+		}
+		rolledBack, err := database.Rollback(database.DB, steps)
+		for _, m := range rolledBack {
+			log.Info(fmt.Sprintf("Rolled back migration %d_%s", m.Version, m.Name))
+		}
+		tools.PanicOnErr(err)
+	case "status":
+		states, err := database.Status(database.DB)
+		tools.PanicOnErr(err)
+		for _, s := range states {
+			appliedAt := "pending"
+			if s.Applied {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%-6d %-40s %s\n", s.Version, s.Name, appliedAt)
+		}
+	default:
+		exit(fmt.Sprintf("unknown command: %s", command))
+	}
+}
+
+func exit(msg string) {
+	fmt.Fprintln(os.Stderr, msg)
+	fmt.Fprintln(os.Stderr, usage)
+	os.Exit(2)
+}
@@ -1,35 +1,98 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
 	"nicccce-acm-calendar-api/config"
+	"nicccce-acm-calendar-api/internal/global/cache"
 	"nicccce-acm-calendar-api/internal/global/database"
+	"nicccce-acm-calendar-api/internal/global/health"
 	"nicccce-acm-calendar-api/internal/global/httpclient"
 	"nicccce-acm-calendar-api/internal/global/logger"
+	"nicccce-acm-calendar-api/internal/global/metrics"
 	"nicccce-acm-calendar-api/internal/global/middleware"
+	"nicccce-acm-calendar-api/internal/global/notify"
+	"nicccce-acm-calendar-api/internal/global/openapi"
+	"nicccce-acm-calendar-api/internal/global/ratelimit"
 	"nicccce-acm-calendar-api/internal/global/redis"
+	"nicccce-acm-calendar-api/internal/global/timezone"
+	"nicccce-acm-calendar-api/internal/global/tracing"
 	"nicccce-acm-calendar-api/internal/module"
 	"nicccce-acm-calendar-api/tools"
-	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 var log *slog.Logger
 
+// defaultShutdownTimeout 未配置 ShutdownTimeout 时的停机等待时间
+const defaultShutdownTimeout = 30 * time.Second
+
+const (
+	apiTitle = "ACM Calendar API"
+	// apiVersion 当前接口版本，路由挂载在 /{Prefix}/{apiVersion} 下
+	apiVersion = "v1"
+)
+
 func Init() {
 	config.Init()
 	log = logger.New("Server")
 	log.Info(fmt.Sprintf("Init Config: %s", config.Get().Mode))
 
+	timezone.Init()
+	log.Info(fmt.Sprintf("Init Timezone: %s", timezone.Default()))
+
+	tracing.Init()
+	log.Info(fmt.Sprintf("Init Tracing: %s", config.Get().Trace.Exporter))
+
 	database.Init()
-	log.Info(fmt.Sprintf("Init Database: %s", config.Get().Mysql.Host))
+	log.Info(fmt.Sprintf("Init Database: %s %s", database.Driver(), database.Name()))
 
 	redis.Init()
-	log.Info(fmt.Sprintf("Init Redis: %s", config.Get().Redis.Host))
+	if redis.Enabled() {
+		log.Info(fmt.Sprintf("Init Redis: %s", config.Get().Redis.Host))
+	} else {
+		log.Warn("Redis is not available, using in-memory fallbacks; only a single instance is supported")
+	}
+
+	cache.Init()
+	if cache.Enabled() {
+		log.Info("Init Cache")
+	} else {
+		log.Info("Response cache is disabled")
+	}
+
+	ratelimit.Init()
+	if config.Get().RateLimit.Disabled {
+		log.Warn("Rate limiting is disabled")
+	} else {
+		log.Info("Init RateLimit")
+	}
+
+	sqlDB, err := database.DB.DB()
+	tools.PanicOnErr(err)
+	metrics.RegisterDB(sqlDB, database.Name())
+	if redis.Enabled() {
+		metrics.RegisterRedis(redis.RedisClient)
+	}
+	log.Info("Init Metrics")
 
 	httpclient.Init()
 	log.Info(fmt.Sprintf("Init HttpClient: %s", config.Get().Host))
 
+	notify.Init()
+	log.Info("Init Notify")
+
 	for _, m := range module.Modules {
 		log.Info(fmt.Sprintf("Init Module: %s", m.GetName()))
 		m.Init()
@@ -40,19 +103,129 @@ func Run() {
 	gin.SetMode(string(config.Get().Mode))
 	r := gin.New()
 
+	r.Use(middleware.RequestID())
+	r.Use(middleware.Language())
 	switch config.Get().Mode {
 	case config.ModeRelease:
 		r.Use(middleware.Logger(logger.Get()))
 	case config.ModeDebug:
 		r.Use(gin.Logger())
 	}
+	r.Use(middleware.Metrics())
+	r.Use(otelgin.Middleware(tracing.ServiceName()))
+	r.Use(middleware.Security())
 	r.Use(middleware.Cors())
 	r.Use(middleware.Recovery())
+	r.Use(middleware.APIKey())
+	r.Use(middleware.Identify())
+	r.Use(middleware.RateLimit())
+
+	// Prometheus 指标，不受 API 前缀影响
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
+	// 路由挂载在版本分组下，不兼容的修改在新版本分组中发布
+	// 未带版本号的旧路径保留为 v1 的别名，响应中带有 Deprecation 响应头
+	api := r.Group("/" + config.Get().Prefix)
+	v1 := api.Group("/" + apiVersion)
+	legacy := api.Group("", middleware.Deprecated(api.BasePath(), v1.BasePath()))
+	var docs []openapi.Route
 	for _, m := range module.Modules {
 		log.Info(fmt.Sprintf("Init Router: %s", m.GetName()))
-		m.InitRouter(r.Group("/" + config.Get().Prefix))
+		m.InitRouter(v1)
+		m.InitRouter(legacy)
+		if d, ok := m.(openapi.Documented); ok {
+			docs = append(docs, d.Docs()...)
+		}
 	}
-	err := r.Run(config.Get().Host + ":" + config.Get().Port)
-	tools.PanicOnErr(err)
+
+	// OpenAPI 文档由已注册的路由和各模块的接口文档生成
+	docs = append(docs,
+		openapi.Route{Method: http.MethodGet, Path: "/openapi.json", Tag: "docs", Summary: "OpenAPI 文档", Raw: true},
+		openapi.Route{Method: http.MethodGet, Path: "/docs", Tag: "docs", Summary: "接口文档页面", Raw: true, ContentType: "text/html"},
+	)
+	spec := openapi.NewHandler(openapi.Info{Title: apiTitle, Version: apiVersion}, r, v1.BasePath(), docs)
+	v1.GET("/openapi.json", spec.Spec)
+	v1.GET("/docs", spec.UI)
+
+	for _, m := range module.Modules {
+		log.Info(fmt.Sprintf("Start Module: %s", m.GetName()))
+		m.Start()
+	}
+
+	srv := &http.Server{
+		Addr:    config.Get().Host + ":" + config.Get().Port,
+		Handler: r,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Info(fmt.Sprintf("Listening on %s", srv.Addr))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case sig := <-quit:
+		log.Info(fmt.Sprintf("Received signal %s, shutting down", sig))
+		drain()
+	case err := <-serveErr:
+		log.Error("Server stopped unexpectedly", "error", err)
+		health.BeginShutdown()
+	}
+
+	shutdown(srv)
+}
+
+// drain 停机前先标记为未就绪，并在 ShutdownDelay 内继续处理请求，等待负载均衡摘除该实例
+func drain() {
+	health.BeginShutdown()
+	delay := time.Duration(config.Get().ShutdownDelay) * time.Second
+	if delay <= 0 {
+		return
+	}
+	log.Info(fmt.Sprintf("Marked as not ready, waiting %s before shutting down", delay))
+	time.Sleep(delay)
+}
+
+// shutdown 优雅停机：排空 HTTP 请求的同时停止各模块，全部结束后再关闭数据库、Redis 和链路追踪
+// 所有步骤共享同一个超时时间
+func shutdown(srv *http.Server) {
+	timeout := time.Duration(config.Get().ShutdownTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// SSE 等长连接要等模块停止后才会断开，因此 HTTP 排空与模块停止并行进行
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := srv.Shutdown(ctx); err != nil {
+			log.Warn("HTTP server did not drain in time", "error", err)
+		}
+	}()
+
+	for i := len(module.Modules) - 1; i >= 0; i-- {
+		m := module.Modules[i]
+		log.Info(fmt.Sprintf("Stop Module: %s", m.GetName()))
+		m.Stop(ctx)
+	}
+	wg.Wait()
+
+	if err := database.Close(); err != nil {
+		log.Warn("Failed to close database", "error", err)
+	}
+	cache.Close()
+	if err := redis.Close(); err != nil {
+		log.Warn("Failed to close redis", "error", err)
+	}
+	if err := tracing.Shutdown(ctx); err != nil {
+		log.Warn("Failed to flush traces", "error", err)
+	}
+	log.Info("Server exited")
 }
@@ -12,10 +12,31 @@ type Config struct {
 	Port   string `envconfig:"PORT"`
 	Prefix string `envconfig:"PREFIX"`
 	Mode   Mode   `envconfig:"MODE"`
-	Mysql  Mysql
-	Redis  Redis
-	JWT    JWT
-	Log    Log
+	// ShutdownTimeout 优雅停机的最长等待时间（秒），默认 30
+	ShutdownTimeout int `envconfig:"SHUTDOWN_TIMEOUT"`
+	// ShutdownDelay 收到停机信号后继续接收请求的时间（秒），期间 /readyz 返回 503，默认 0
+	ShutdownDelay int `envconfig:"SHUTDOWN_DELAY"`
+	// Timezone 默认时区，用于解析时间参数和输出比赛时间，为空时使用服务器本地时区
+	Timezone  string `envconfig:"TIMEZONE"`
+	Database  Database
+	Mysql     Mysql
+	Redis     Redis
+	JWT       JWT
+	Log       Log
+	Crawler   Crawler
+	Cache     Cache
+	RateLimit RateLimit
+	Cors      Cors
+	Security  Security
+	Notify    Notify
+	Trace     Trace
+}
+
+type Database struct {
+	Driver string `envconfig:"DB_DRIVER"` // 数据库驱动：mysql（默认）、postgres、sqlite
+	DSN    string `envconfig:"DB_DSN"`    // 连接串；mysql 为空时由 Mysql 配置拼接，sqlite 为数据库文件路径
+	// SkipMigrate 启动时不执行数据库迁移，需通过 migrate 子命令手动执行
+	SkipMigrate bool `envconfig:"DB_SKIP_MIGRATE"`
 }
 
 type Mysql struct {
@@ -46,3 +67,67 @@ type Log struct {
 	MaxAge     int    `envconfig:"LOG_MAX_AGE"`     // 日志文件保留天数
 	Compress   bool   `envconfig:"LOG_COMPRESS"`    // 是否压缩旧日志文件
 }
+
+type Crawler struct {
+	JobWorkers       int `envconfig:"CRAWLER_JOB_WORKERS"`       // 异步刷新任务的工作协程数，默认 2
+	PlatformTimeout  int `envconfig:"CRAWLER_PLATFORM_TIMEOUT"`  // 单个平台刷新的超时时间（秒），默认 120
+	MaxAttempts      int `envconfig:"CRAWLER_MAX_ATTEMPTS"`      // 单次刷新最多尝试次数（含首次），默认 3
+	RetryBaseDelay   int `envconfig:"CRAWLER_RETRY_BASE_DELAY"`  // 重试退避的初始等待时间（毫秒），默认 500
+	RetryMaxDelay    int `envconfig:"CRAWLER_RETRY_MAX_DELAY"`   // 重试退避的最大等待时间（毫秒），默认 10000
+	BreakerThreshold int `envconfig:"CRAWLER_BREAKER_THRESHOLD"` // 连续多少次刷新失败后打开熔断器，重试不单独计数，默认 3
+	BreakerCooldown  int `envconfig:"CRAWLER_BREAKER_COOLDOWN"`  // 熔断器打开后多久进行探测（秒），默认 600
+
+	HealthFailureThreshold int     `envconfig:"CRAWLER_HEALTH_FAILURE_THRESHOLD"` // 连续失败多少次判定为失败，默认 3
+	HealthDegradedRatio    float64 `envconfig:"CRAWLER_HEALTH_DEGRADED_RATIO"`    // 爬取数量低于历史基线的该比例时判定为异常，默认 0.3
+	HealthParseErrorRate   float64 `envconfig:"CRAWLER_HEALTH_PARSE_ERROR_RATE"`  // 解析失败率超过该值时判定为异常，默认 0.2
+	HealthStaleHours       int     `envconfig:"CRAWLER_HEALTH_STALE_HOURS"`       // 超过多少小时未成功刷新判定为失败，默认 48
+}
+
+type Cache struct {
+	TTL    int `envconfig:"CACHE_TTL"`     // 响应缓存的过期时间（秒），默认 300，负数关闭缓存
+	MaxAge int `envconfig:"CACHE_MAX_AGE"` // 响应头 Cache-Control 的 max-age（秒），默认 0，即客户端每次都向服务端验证
+}
+
+type RateLimit struct {
+	Disabled bool `envconfig:"RATE_LIMIT_DISABLED"` // 关闭限流
+	// Policies 限流策略，键为策略名，可覆盖内置策略 refresh、refresh_job 的部分字段，仅支持通过配置文件设置
+	Policies map[string]RateLimitPolicy
+	// Routes 路由到策略名的映射，键为 "METHOD /path" 或 "/path"，路径相对于 API 前缀且不含版本号，以 /* 结尾时匹配子路径；策略名为 none 时不限流
+	Routes map[string]string `envconfig:"RATE_LIMIT_ROUTES"`
+}
+
+type RateLimitPolicy struct {
+	Limit  int    // 窗口内允许的请求数
+	Window int    // 窗口长度（秒）
+	By     string // 限流维度：ip（默认）、user、api_key，请求没有对应的用户或 API Key 时按 IP 限流
+}
+
+type Cors struct {
+	// AllowOrigins 允许跨域请求的来源，例如 "https://acm.example.com"，支持 "https://*.example.com" 匹配子域名；
+	// "*" 表示允许所有来源（不能与 AllowCredentials 同时使用），为空时默认为 "*"
+	AllowOrigins     []string `envconfig:"CORS_ALLOW_ORIGINS"`
+	AllowMethods     []string `envconfig:"CORS_ALLOW_METHODS"`     // 允许的请求方法，为空时使用默认值
+	AllowHeaders     []string `envconfig:"CORS_ALLOW_HEADERS"`     // 允许的请求头，为空时使用默认值
+	ExposeHeaders    []string `envconfig:"CORS_EXPOSE_HEADERS"`    // 额外暴露给前端的响应头，请求ID、ETag、限流等响应头总是暴露
+	AllowCredentials bool     `envconfig:"CORS_ALLOW_CREDENTIALS"` // 是否允许携带 Cookie 等凭据
+	MaxAge           int      `envconfig:"CORS_MAX_AGE"`           // 预检请求结果的缓存时间（秒），默认 600，负数表示不缓存
+}
+
+type Security struct {
+	// ContentSecurityPolicy 响应头 Content-Security-Policy，为空时使用仅适用于 JSON 接口的严格策略
+	ContentSecurityPolicy string `envconfig:"SECURITY_CSP"`
+	ReferrerPolicy        string `envconfig:"SECURITY_REFERRER_POLICY"` // 响应头 Referrer-Policy，默认 strict-origin-when-cross-origin
+	HSTSMaxAge            int    `envconfig:"SECURITY_HSTS_MAX_AGE"`    // Strict-Transport-Security 的 max-age（秒），0 表示不发送，仅应在全站 HTTPS 时开启
+}
+
+type Trace struct {
+	Exporter    string  `envconfig:"TRACE_EXPORTER"`     // 链路追踪导出器：空（关闭）、stdout、otlp
+	Endpoint    string  `envconfig:"TRACE_ENDPOINT"`     // OTLP HTTP 接收端地址，例如 "otel-collector:4318"
+	Insecure    bool    `envconfig:"TRACE_INSECURE"`     // OTLP 是否使用 HTTP 而非 HTTPS
+	ServiceName string  `envconfig:"TRACE_SERVICE_NAME"` // 上报的服务名，默认 nicccce-acm-calendar-api
+	SampleRatio float64 `envconfig:"TRACE_SAMPLE_RATIO"` // 采样比例（0~1），0 或 1 表示全部采样
+}
+
+type Notify struct {
+	WebhookURL string `envconfig:"NOTIFY_WEBHOOK_URL"` // 告警 Webhook 地址，为空时仅输出到日志
+}
@@ -0,0 +1,159 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"nicccce-acm-calendar-api/internal/global/cache"
+	"nicccce-acm-calendar-api/internal/global/database"
+	"nicccce-acm-calendar-api/internal/global/ratelimit"
+	"nicccce-acm-calendar-api/internal/model"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Header 携带 API Key 的请求头
+	Header = "X-API-Key"
+	// ContextKey 是认证通过后在 gin.Context 中保存 *model.APIKey 的键
+	ContextKey = "api_key"
+
+	// keyPrefix API Key 原文的固定前缀，便于在日志和代码仓库中识别泄露的 Key
+	keyPrefix = "acm_"
+	// displayPrefixLen 保存和展示的 Key 前缀长度，用于辨认 Key
+	displayPrefixLen = len(keyPrefix) + 8
+	// keyBytes API Key 随机部分的字节数
+	keyBytes = 32
+)
+
+// keyLen API Key 原文的长度：前缀加上随机部分的 base64url 编码
+var keyLen = len(keyPrefix) + base64.RawURLEncoding.EncodedLen(keyBytes)
+
+// errKeyNotFound 查询的 API Key 不存在，不写入缓存
+var errKeyNotFound = errors.New("api key not found")
+
+// 授权范围
+const (
+	// ScopeContestsRead 查询比赛、平台和订阅事件
+	ScopeContestsRead = "contests:read"
+	// ScopeRefresh 触发数据刷新
+	ScopeRefresh = "refresh"
+	// ScopeAdmin 管理比赛和 API Key
+	ScopeAdmin = "admin"
+)
+
+// Scopes 所有授权范围
+var Scopes = []string{ScopeContestsRead, ScopeRefresh, ScopeAdmin}
+
+// 配额使用的限流策略，API Key 设置了 RateLimit 或 DailyQuota 时覆盖对应策略的上限
+const (
+	PolicyMinute = "api_key"
+	PolicyDaily  = "api_key_daily"
+)
+
+// ValidScope 是否为有效的授权范围
+func ValidScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Generate 生成新的 API Key，返回原文、用于辨认的前缀和摘要
+func Generate() (key, prefix, hash string, err error) {
+	buf := make([]byte, keyBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", err
+	}
+	key = keyPrefix + base64.RawURLEncoding.EncodeToString(buf)
+	return key, key[:displayPrefixLen], Hash(key), nil
+}
+
+// Hash 计算 API Key 的摘要，数据库中只保存摘要
+// Key 本身是高熵随机串，无需加盐或使用慢哈希
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Tag API Key 查询结果的缓存标签，修改或吊销 API Key 后需使其失效
+func Tag(hash string) string {
+	return "api_key:" + hash
+}
+
+// wellFormed Key 是否符合 Generate 生成的格式：固定前缀加上固定长度的 base64url 字符
+func wellFormed(key string) bool {
+	if len(key) != keyLen || !strings.HasPrefix(key, keyPrefix) {
+		return false
+	}
+	for _, ch := range key[len(keyPrefix):] {
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9', ch == '-', ch == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Authenticate 校验 API Key，返回可用的 API Key；Key 格式错误、不存在、已吊销或已过期时返回 nil
+// 格式错误的 Key 不查询数据库；存在的 Key 的查询结果经过响应缓存，不存在的 Key 不缓存，
+// 避免随意构造的 Key 占满缓存
+func Authenticate(ctx context.Context, key string) (*model.APIKey, error) {
+	if !wellFormed(key) {
+		return nil, nil
+	}
+	hash := Hash(key)
+	found, _, err := cache.Load(ctx, Tag(hash), []string{Tag(hash)}, func() (model.APIKey, error) {
+		var k model.APIKey
+		err := database.DB.WithContext(ctx).Where("key_hash = ?", hash).Take(&k).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return k, errKeyNotFound
+		}
+		return k, err
+	})
+	if errors.Is(err, errKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !found.ActiveAt(time.Now()) {
+		return nil, nil
+	}
+	return &found, nil
+}
+
+// Quotas 返回 API Key 适用的配额策略
+func Quotas(key *model.APIKey) []*ratelimit.Policy {
+	overrides := []struct {
+		name  string
+		limit int
+	}{
+		{PolicyMinute, key.RateLimit},
+		{PolicyDaily, key.DailyQuota},
+	}
+
+	quotas := make([]*ratelimit.Policy, 0, len(overrides))
+	for _, o := range overrides {
+		policy, ok := ratelimit.Get(o.name)
+		if !ok {
+			continue
+		}
+		if o.limit > 0 {
+			p := *policy
+			p.Limit = o.limit
+			policy = &p
+		}
+		quotas = append(quotas, policy)
+	}
+	return quotas
+}
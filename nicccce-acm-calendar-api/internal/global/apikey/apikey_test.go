@@ -0,0 +1,49 @@
+package apikey
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	key, prefix, hash, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !wellFormed(key) {
+		t.Fatalf("generated key %q is not well formed", key)
+	}
+	if !strings.HasPrefix(key, prefix) || len(prefix) != displayPrefixLen {
+		t.Fatalf("prefix = %q, want the first %d characters of %q", prefix, displayPrefixLen, key)
+	}
+	if hash != Hash(key) {
+		t.Fatalf("hash = %q, want %q", hash, Hash(key))
+	}
+}
+
+func TestWellFormed(t *testing.T) {
+	valid := "acm_AR99I2aS5UsOMHCZd-A4e6FySxEFYzlhQCAXaf7Y224"
+
+	tests := []struct {
+		name string
+		key  string
+		want bool
+	}{
+		{"valid", valid, true},
+		{"empty", "", false},
+		{"prefix only", "acm_", false},
+		{"wrong prefix", "xyz_" + valid[4:], false},
+		{"too short", valid[:len(valid)-1], false},
+		{"too long", valid + "A", false},
+		{"padding", valid[:len(valid)-1] + "=", false},
+		{"standard base64", valid[:len(valid)-1] + "+", false},
+		{"non ascii", valid[:len(valid)-3] + "密", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wellFormed(tt.key); got != tt.want {
+				t.Fatalf("wellFormed(%q) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package apikey
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"nicccce-acm-calendar-api/internal/global/cache"
+	"nicccce-acm-calendar-api/internal/global/database"
+	"nicccce-acm-calendar-api/internal/model"
+)
+
+// Create 按 key 中的名称、配额和过期时间创建 API Key 并返回 Key 原文
+// 未指定授权范围时只授予 contests:read
+func Create(ctx context.Context, key *model.APIKey, scopes []string) (string, error) {
+	if len(scopes) == 0 {
+		scopes = []string{ScopeContestsRead}
+	}
+	joined, err := JoinScopes(scopes)
+	if err != nil {
+		return "", err
+	}
+
+	raw, prefix, hash, err := Generate()
+	if err != nil {
+		return "", err
+	}
+	key.Prefix = prefix
+	key.KeyHash = hash
+	key.Scopes = joined
+	if err := database.DB.WithContext(ctx).Create(key).Error; err != nil {
+		return "", err
+	}
+	// 创建前可能已用同一 Key 请求过，清除缓存的查询结果
+	cache.Invalidate(ctx, Tag(hash))
+	return raw, nil
+}
+
+// Save 保存对 API Key 的修改并使缓存的查询结果失效
+func Save(ctx context.Context, key *model.APIKey) error {
+	if err := database.DB.WithContext(ctx).Save(key).Error; err != nil {
+		return err
+	}
+	cache.Invalidate(ctx, Tag(key.KeyHash))
+	return nil
+}
+
+// Revoke 吊销 API Key，已吊销的保持原吊销时间
+func Revoke(ctx context.Context, key *model.APIKey) error {
+	if key.RevokedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	key.RevokedAt = &now
+	return Save(ctx, key)
+}
+
+// JoinScopes 校验授权范围并转换为保存格式，重复的授权范围只保留一个
+func JoinScopes(scopes []string) (string, error) {
+	seen := make(map[string]bool, len(scopes))
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if !ValidScope(scope) {
+			return "", fmt.Errorf("invalid scope %q, available scopes: %s", scope, strings.Join(Scopes, ", "))
+		}
+		if seen[scope] {
+			continue
+		}
+		seen[scope] = true
+		result = append(result, scope)
+	}
+	return strings.Join(result, ","), nil
+}
@@ -0,0 +1,143 @@
+package apikey
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"nicccce-acm-calendar-api/internal/global/database"
+	"nicccce-acm-calendar-api/internal/global/logger"
+	"nicccce-acm-calendar-api/internal/global/timezone"
+	"nicccce-acm-calendar-api/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// flushInterval 请求统计写入数据库的间隔
+const flushInterval = 30 * time.Second
+
+// 请求统计先在内存中累计，定期批量写入数据库，避免每个请求都写库
+// 进程异常退出时最多丢失一个间隔内的统计
+var (
+	log *slog.Logger
+
+	usageMu  sync.Mutex
+	usage    = make(map[usageKey]*usageCount)
+	lastUsed = make(map[uint]time.Time)
+
+	stop chan struct{}
+	done chan struct{}
+)
+
+type usageKey struct {
+	id   uint
+	date string
+}
+
+type usageCount struct {
+	requests int64
+	rejected int64
+}
+
+// Record 记录一次使用 API Key 的请求，rejected 表示因超出配额被拒绝
+func Record(id uint, rejected bool) {
+	now := time.Now()
+	k := usageKey{id: id, date: now.In(timezone.Default()).Format(time.DateOnly)}
+
+	usageMu.Lock()
+	defer usageMu.Unlock()
+	count, ok := usage[k]
+	if !ok {
+		count = &usageCount{}
+		usage[k] = count
+	}
+	count.requests++
+	if rejected {
+		count.rejected++
+	}
+	lastUsed[id] = now
+}
+
+// Start 启动定期写入请求统计的协程
+func Start() {
+	log = logger.New("APIKey")
+	stop = make(chan struct{})
+	done = make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(flushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				flush(context.Background())
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止定期写入并写入剩余的请求统计
+func Stop(ctx context.Context) {
+	if stop == nil {
+		return
+	}
+	close(stop)
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	flush(ctx)
+}
+
+// flush 将内存中累计的请求统计写入数据库，写入失败的统计合并回内存等待下次写入
+func flush(ctx context.Context) {
+	usageMu.Lock()
+	pending, used := usage, lastUsed
+	usage = make(map[usageKey]*usageCount)
+	lastUsed = make(map[uint]time.Time)
+	usageMu.Unlock()
+	if len(pending) == 0 && len(used) == 0 {
+		return
+	}
+
+	db := database.DB.WithContext(ctx)
+	for k, count := range pending {
+		row := model.APIKeyUsage{APIKeyID: k.id, Date: k.date, Requests: count.requests, Rejected: count.rejected}
+		// 带表名引用原值，PostgreSQL 中不带表名时与 excluded 有歧义
+		err := db.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "api_key_id"}, {Name: "date"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"requests": gorm.Expr("api_key_usages.requests + ?", count.requests),
+				"rejected": gorm.Expr("api_key_usages.rejected + ?", count.rejected),
+			}),
+		}).Create(&row).Error
+		if err != nil {
+			log.WarnContext(ctx, "Failed to save api key usage", "api_key_id", k.id, "error", err)
+			restore(k, count)
+		}
+	}
+
+	for id, t := range used {
+		// 不修改 updated_at，最后使用时间不属于 API Key 配置的变化
+		if err := db.Model(&model.APIKey{}).Where("id = ?", id).
+			UpdateColumn("last_used_at", t).Error; err != nil {
+			log.WarnContext(ctx, "Failed to update api key last used time", "api_key_id", id, "error", err)
+		}
+	}
+}
+
+func restore(k usageKey, count *usageCount) {
+	usageMu.Lock()
+	defer usageMu.Unlock()
+	if existing, ok := usage[k]; ok {
+		existing.requests += count.requests
+		existing.rejected += count.rejected
+		return
+	}
+	usage[k] = count
+}
@@ -0,0 +1,321 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"nicccce-acm-calendar-api/config"
+	"nicccce-acm-calendar-api/internal/global/logger"
+	"nicccce-acm-calendar-api/internal/global/metrics"
+	redisclient "nicccce-acm-calendar-api/internal/global/redis"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	entryKeyPrefix = "cache:entry:"
+	tagKeyPrefix   = "cache:tag:"
+
+	// invalidateChannel 是用于在多个API实例之间广播标签失效的Redis频道
+	invalidateChannel = "acm-calendar:cache"
+
+	defaultTTL = 300 * time.Second
+
+	// versionTTL 本地记录的标签版本的有效期，过期后重新从 Redis 读取
+	// 防止订阅断线重连期间错过失效广播后长期使用旧版本
+	versionTTL = 30 * time.Second
+)
+
+// 缓存按标签失效：每个标签在 Redis 中有一个递增的版本号，缓存键包含其依赖标签的当前版本，
+// 失效时递增版本号，旧缓存不再被访问，随过期时间自然清除。
+// 各实例在本地记录标签版本以减少 Redis 访问，失效时通过 Redis 频道广播新版本。
+var (
+	log *slog.Logger
+	ttl time.Duration
+
+	mu       sync.RWMutex
+	versions = make(map[string]tagVersion)
+
+	flights singleflight.Group
+	pubsub  *redis.PubSub
+	cancel  context.CancelFunc
+)
+
+type tagVersion struct {
+	value     int64
+	fetchedAt time.Time
+}
+
+// Init 读取缓存配置，配置了 Redis 时订阅失效广播
+func Init() {
+	log = logger.New("Cache")
+
+	ttl = time.Duration(config.Get().Cache.TTL) * time.Second
+	if ttl == 0 {
+		ttl = defaultTTL
+	}
+	if !Enabled() || !redisclient.Enabled() {
+		return
+	}
+
+	ctx, c := context.WithCancel(context.Background())
+	cancel = c
+	pubsub = redisclient.RedisClient.Subscribe(ctx, invalidateChannel)
+
+	go func() {
+		for msg := range pubsub.Channel() {
+			var updated map[string]int64
+			if err := json.Unmarshal([]byte(msg.Payload), &updated); err != nil {
+				log.Warn("Failed to decode cache invalidation", "error", err)
+				continue
+			}
+			observe(updated)
+		}
+	}()
+}
+
+// Close 取消失效广播的订阅
+func Close() {
+	if cancel != nil {
+		cancel()
+	}
+	if pubsub != nil {
+		_ = pubsub.Close()
+	}
+}
+
+// Enabled 是否启用缓存
+func Enabled() bool {
+	return ttl > 0
+}
+
+// Load 读取缓存，未命中时调用 fill 生成并写入缓存
+// tags 为缓存内容依赖的标签，其中任一标签失效后不再命中；digest 为缓存内容的摘要，可用于生成 ETag
+// 同一实例上相同缓存的并发未命中只调用一次 fill
+func Load[T any](ctx context.Context, key string, tags []string, fill func() (T, error)) (value T, digest string, err error) {
+	if !Enabled() {
+		return loadUncached(fill)
+	}
+
+	vers, err := tagVersions(ctx, tags)
+	if err != nil {
+		log.WarnContext(ctx, "Failed to load cache tag versions", "error", err)
+		return loadUncached(fill)
+	}
+	entryKey := entryKey(key, tags, vers)
+
+	if data, ok := get(ctx, entryKey); ok {
+		if err := json.Unmarshal(data, &value); err == nil {
+			metrics.CacheRequests.WithLabelValues("hit").Inc()
+			return value, digestOf(data), nil
+		}
+	}
+	metrics.CacheRequests.WithLabelValues("miss").Inc()
+
+	v, err, _ := flights.Do(entryKey, func() (any, error) {
+		value, err := fill()
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		set(ctx, entryKey, data)
+		return data, nil
+	})
+	if err != nil {
+		return value, "", err
+	}
+
+	// 命中与未命中都从序列化结果解码，返回的值一致且不与其他调用方共享
+	data := v.([]byte)
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, "", err
+	}
+	return value, digestOf(data), nil
+}
+
+func loadUncached[T any](fill func() (T, error)) (T, string, error) {
+	value, err := fill()
+	if err != nil {
+		return value, "", err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value, "", err
+	}
+	return value, digestOf(data), nil
+}
+
+// Invalidate 使依赖这些标签的缓存全部失效，并通知其他实例
+// 应在数据变更提交之后调用
+func Invalidate(ctx context.Context, tags ...string) {
+	if !Enabled() || len(tags) == 0 {
+		return
+	}
+	tags = unique(tags)
+	metrics.CacheInvalidations.Add(float64(len(tags)))
+
+	if !redisclient.Enabled() {
+		mu.Lock()
+		for _, tag := range tags {
+			v := versions[tag]
+			v.value++
+			versions[tag] = v
+		}
+		mu.Unlock()
+		return
+	}
+
+	// 请求结束不应影响已提交数据的缓存失效
+	ctx = context.WithoutCancel(ctx)
+	pipe := redisclient.RedisClient.Pipeline()
+	cmds := make([]*redis.IntCmd, len(tags))
+	for i, tag := range tags {
+		cmds[i] = pipe.Incr(ctx, tagKeyPrefix+tag)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		// 无法确认新版本时丢弃本地版本，下次访问重新读取
+		log.WarnContext(ctx, "Failed to invalidate cache", "tags", tags, "error", err)
+		forget(tags)
+		return
+	}
+
+	updated := make(map[string]int64, len(tags))
+	for i, tag := range tags {
+		updated[tag] = cmds[i].Val()
+	}
+	observe(updated)
+
+	payload, err := json.Marshal(updated)
+	if err != nil {
+		log.WarnContext(ctx, "Failed to encode cache invalidation", "error", err)
+		return
+	}
+	if err := redisclient.RedisClient.Publish(ctx, invalidateChannel, payload).Err(); err != nil {
+		// 其他实例最迟在本地版本过期后读取到新版本
+		log.WarnContext(ctx, "Failed to broadcast cache invalidation", "error", err)
+	}
+}
+
+// tagVersions 获取标签的当前版本，优先使用本地记录，缺失或过期的从 Redis 读取
+func tagVersions(ctx context.Context, tags []string) ([]int64, error) {
+	result := make([]int64, len(tags))
+	now := time.Now()
+
+	var missing []int
+	mu.RLock()
+	for i, tag := range tags {
+		v, ok := versions[tag]
+		// 未配置 Redis 时本地记录即为全部版本
+		if !redisclient.Enabled() || (ok && now.Sub(v.fetchedAt) < versionTTL) {
+			result[i] = v.value
+			continue
+		}
+		missing = append(missing, i)
+	}
+	mu.RUnlock()
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	keys := make([]string, len(missing))
+	for j, i := range missing {
+		keys[j] = tagKeyPrefix + tags[i]
+	}
+	values, err := redisclient.RedisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	fetched := make(map[string]int64, len(missing))
+	for j, i := range missing {
+		// 从未失效过的标签在 Redis 中不存在，版本为 0
+		if s, ok := values[j].(string); ok {
+			n, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cache tag version %q: %w", s, err)
+			}
+			result[i] = n
+		}
+		fetched[tags[i]] = result[i]
+	}
+	observe(fetched)
+	return result, nil
+}
+
+// observe 记录标签的最新版本，版本只增不减，乱序到达的旧版本会被忽略
+func observe(updated map[string]int64) {
+	now := time.Now()
+	mu.Lock()
+	defer mu.Unlock()
+	for tag, value := range updated {
+		if v, ok := versions[tag]; ok && v.value > value {
+			value = v.value
+		}
+		versions[tag] = tagVersion{value: value, fetchedAt: now}
+	}
+}
+
+func forget(tags []string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, tag := range tags {
+		delete(versions, tag)
+	}
+}
+
+func get(ctx context.Context, key string) ([]byte, bool) {
+	data, err := redisclient.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			log.WarnContext(ctx, "Failed to read cache", "error", err)
+		}
+		return nil, false
+	}
+	return []byte(data), true
+}
+
+func set(ctx context.Context, key string, data []byte) {
+	if err := redisclient.Set(ctx, key, string(data), ttl); err != nil {
+		log.WarnContext(ctx, "Failed to write cache", "error", err)
+	}
+}
+
+// entryKey 由缓存键和依赖标签的版本生成存储键
+func entryKey(key string, tags []string, vers []int64) string {
+	h := sha256.New()
+	h.Write([]byte(key))
+	for i, tag := range tags {
+		fmt.Fprintf(h, "|%s=%d", tag, vers[i])
+	}
+	return entryKeyPrefix + hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+func digestOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+func unique(tags []string) []string {
+	seen := make(map[string]struct{}, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		result = append(result, tag)
+	}
+	return result
+}
@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"nicccce-acm-calendar-api/config"
+)
+
+// ETag 由缓存内容的摘要和影响响应内容的请求参数（如语言、时区）生成弱 ETag
+// 弱 ETag 表示语义等价；响应中有随时间变化的字段（如倒计时）时，调用方应将所在的时间段作为 variants 之一
+func ETag(digest string, variants ...string) string {
+	h := sha256.New()
+	h.Write([]byte(digest))
+	for _, v := range variants {
+		fmt.Fprintf(h, "|%s", v)
+	}
+	return `W/"` + hex.EncodeToString(h.Sum(nil)[:8]) + `"`
+}
+
+// NotModified 设置 ETag 和 Cache-Control 响应头，请求的 If-None-Match 与 etag 匹配时响应 304 并返回 true
+// expires 为响应内容失效的时间，max-age 不超过距该时间的秒数；为零值时不限制
+func NotModified(c *gin.Context, etag string, expires time.Time) bool {
+	c.Header("ETag", etag)
+	maxAge := config.Get().Cache.MaxAge
+	if !expires.IsZero() {
+		maxAge = min(maxAge, int(time.Until(expires)/time.Second))
+	}
+	if maxAge > 0 {
+		c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", maxAge))
+	} else {
+		c.Header("Cache-Control", "no-cache")
+	}
+
+	if !matchETag(c.GetHeader("If-None-Match"), etag) {
+		return false
+	}
+	c.AbortWithStatus(http.StatusNotModified)
+	return true
+}
+
+// matchETag 按弱比较判断 If-None-Match 是否包含 etag
+func matchETag(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
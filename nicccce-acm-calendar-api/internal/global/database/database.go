@@ -0,0 +1,145 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	gormtracing "gorm.io/plugin/opentelemetry/tracing"
+	"nicccce-acm-calendar-api/config"
+	applogger "nicccce-acm-calendar-api/internal/global/logger"
+	"nicccce-acm-calendar-api/tools"
+)
+
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+
+	defaultSQLitePath = "data/acm-calendar.db"
+)
+
+var DB *gorm.DB
+
+// Init 连接数据库并执行未执行的迁移
+// 多实例部署时建议设置 Database.SkipMigrate，在发布前通过 migrate 子命令统一执行迁移
+func Init() {
+	Open()
+
+	if config.Get().Database.SkipMigrate {
+		return
+	}
+	ran, err := Migrate(DB)
+	tools.PanicOnErr(err)
+	log := applogger.New("Database")
+	for _, m := range ran {
+		log.Info(fmt.Sprintf("Applied migration %d_%s", m.Version, m.Name))
+	}
+}
+
+// Open 连接数据库，不执行迁移
+func Open() {
+	gormConfig := &gorm.Config{
+		//NamingStrategy: schema.NamingStrategy{SingularTable: true}, // 还是单数表名好
+	}
+
+	switch config.Get().Mode {
+	case config.ModeDebug:
+		gormConfig.Logger = logger.Default.LogMode(logger.Info)
+	case config.ModeRelease:
+		gormConfig.Logger = logger.Discard
+	}
+
+	dialector, err := newDialector()
+	tools.PanicOnErr(err)
+
+	db, err := gorm.Open(dialector, gormConfig)
+	tools.PanicOnErr(err)
+	DB = db
+
+	if Driver() == DriverSQLite {
+		// SQLite 同一时刻只允许一个写入者，多连接并发写入会返回 SQLITE_BUSY，这里串行化所有访问
+		sqlDB, err := DB.DB()
+		tools.PanicOnErr(err)
+		sqlDB.SetMaxOpenConns(1)
+	}
+
+	// 为每条 SQL 生成 span，需配合 DB.WithContext(ctx) 使用才能挂到调用方的链路上
+	tools.PanicOnErr(DB.Use(gormtracing.NewPlugin(gormtracing.WithoutMetrics())))
+}
+
+// Driver 当前使用的数据库驱动
+func Driver() string {
+	if driver := config.Get().Database.Driver; driver != "" {
+		return driver
+	}
+	return DriverMySQL
+}
+
+// Name 数据库名称，用于日志和监控标签
+func Name() string {
+	switch Driver() {
+	case DriverMySQL:
+		return config.Get().Mysql.DBName
+	case DriverSQLite:
+		return sqlitePath()
+	default:
+		return Driver()
+	}
+}
+
+// Close 关闭数据库连接池
+func Close() error {
+	if DB == nil {
+		return nil
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+func newDialector() (gorm.Dialector, error) {
+	dsn := config.Get().Database.DSN
+
+	switch Driver() {
+	case DriverMySQL:
+		if dsn == "" {
+			dsn = fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+				config.Get().Mysql.Username,
+				config.Get().Mysql.Password,
+				config.Get().Mysql.Host,
+				config.Get().Mysql.Port,
+				config.Get().Mysql.DBName,
+			)
+		}
+		return mysql.Open(dsn), nil
+	case DriverPostgres:
+		if dsn == "" {
+			return nil, fmt.Errorf("database dsn is required for driver %s", DriverPostgres)
+		}
+		return postgres.Open(dsn), nil
+	case DriverSQLite:
+		path := sqlitePath()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, err
+		}
+		// 等待锁而不是立即返回 SQLITE_BUSY，并开启外键约束
+		return sqlite.Open(path + "?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)"), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", Driver())
+	}
+}
+
+func sqlitePath() string {
+	if dsn := config.Get().Database.DSN; dsn != "" {
+		return dsn
+	}
+	return defaultSQLitePath
+}
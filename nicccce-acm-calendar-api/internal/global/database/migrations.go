@@ -0,0 +1,393 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"nicccce-acm-calendar-api/internal/global/search"
+)
+
+// migrations 所有数据库迁移，新增迁移时追加到末尾并使用更大的版本号
+// 迁移中使用各自的结构体快照而不是 model 包中的模型，保证模型后续变化不会改变已发布迁移的行为
+var migrations = []Migration{
+	{
+		// 初始表结构，对应此前 AutoMigrate 的模型
+		// 使用 AutoMigrate 而不是 CreateTable，使已由旧版本自动建表的数据库可以直接纳入迁移管理
+		Version: 1,
+		Name:    "create_initial_tables",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&v1Contest{}, &v1ContestPlatform{}, &v1ContestRefreshLog{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v1ContestRefreshLog{}, &v1ContestPlatform{}, &v1Contest{})
+		},
+	},
+	{
+		// 比赛按 (platform, source_id) 唯一，添加唯一索引前先清理重复数据
+		Version: 2,
+		Name:    "unique_contest_platform_source_id",
+		Up: func(tx *gorm.DB) error {
+			if err := dedupeContests(tx); err != nil {
+				return err
+			}
+			if err := tx.Migrator().CreateIndex(&v2Contest{}, "idx_contests_platform_source_id"); err != nil {
+				return err
+			}
+			if tx.Migrator().HasIndex(&v1Contest{}, "idx_contests_source_id") {
+				return tx.Migrator().DropIndex(&v1Contest{}, "idx_contests_source_id")
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().CreateIndex(&v1Contest{}, "idx_contests_source_id"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropIndex(&v2Contest{}, "idx_contests_platform_source_id")
+		},
+	},
+	{
+		// 添加搜索文本列，并为已有比赛生成搜索文本
+		Version: 3,
+		Name:    "add_contest_search_text",
+		Up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&v3Contest{}, "SearchText") {
+				if err := tx.Migrator().AddColumn(&v3Contest{}, "SearchText"); err != nil {
+					return err
+				}
+			}
+			return backfillSearchText(tx)
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&v3Contest{}, "SearchText")
+		},
+	},
+	{
+		// 平台名称统一为平台标识，并为平台表添加显示名称、主页、图标等列
+		Version: 4,
+		Name:    "normalize_platform_slugs",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range v4PlatformColumns {
+				if tx.Migrator().HasColumn(&v4ContestPlatform{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&v4ContestPlatform{}, column); err != nil {
+					return err
+				}
+			}
+			return normalizePlatforms(tx)
+		},
+		Down: func(tx *gorm.DB) error {
+			// 平台名称无法还原，只移除新增的列
+			for _, column := range v4PlatformColumns {
+				if err := tx.Migrator().DropColumn(&v4ContestPlatform{}, column); err != nil {
+					return err
+				}
+			}
+			// SQLite 删除列时会重建表，表上的索引随之丢失
+			for _, field := range []string{"Name", "DeletedAt"} {
+				if tx.Migrator().HasIndex(&v1ContestPlatform{}, field) {
+					continue
+				}
+				if err := tx.Migrator().CreateIndex(&v1ContestPlatform{}, field); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		// API Key 及其每日请求统计
+		Version: 5,
+		Name:    "create_api_keys",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&v5APIKey{}, &v5APIKeyUsage{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v5APIKeyUsage{}, &v5APIKey{})
+		},
+	},
+	{
+		// 刷新日志记录获取的比赛数量，更新数量改为只统计内容发生变化的比赛
+		// 此前的更新数量包含所有已存在的比赛，新增与更新之和即为获取数量
+		Version: 6,
+		Name:    "add_refresh_log_fetched_count",
+		Up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&v6ContestRefreshLog{}, "FetchedCount") {
+				if err := tx.Migrator().AddColumn(&v6ContestRefreshLog{}, "FetchedCount"); err != nil {
+					return err
+				}
+			}
+			return tx.Model(&v6ContestRefreshLog{}).
+				Where("fetched_count = 0").
+				Update("fetched_count", gorm.Expr("new_count + updated_count")).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&v6ContestRefreshLog{}, "FetchedCount")
+		},
+	},
+	{
+		// 用户偏好设置，目前只有默认时区
+		Version: 7,
+		Name:    "create_user_preferences",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&v7UserPreference{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v7UserPreference{})
+		},
+	},
+}
+
+// v4PlatformColumns 版本 4 为平台表新增的列
+var v4PlatformColumns = []string{"DisplayNameEn", "Homepage", "Icon", "Color", "SortOrder"}
+
+// v4PlatformSlugs 版本 4 之前写入过的平台名称到平台标识的映射
+var v4PlatformSlugs = map[string]string{
+	"Codeforces": "codeforces",
+	"AtCoder":    "atcoder",
+	"LeetCode":   "leetcode",
+	"NowCoder":   "nowcoder",
+	"牛客":         "nowcoder",
+	"Luogu":      "luogu",
+	"洛谷":         "luogu",
+}
+
+// normalizePlatforms 将比赛、刷新日志和平台表中的平台名称替换为平台标识
+// 同一比赛在新旧名称下各有一条记录时保留标识下的记录，平台表同理
+func normalizePlatforms(tx *gorm.DB) error {
+	for name, slug := range v4PlatformSlugs {
+		var sourceIDs []string
+		if err := tx.Unscoped().Model(&v3Contest{}).
+			Where("platform = ?", slug).
+			Pluck("source_id", &sourceIDs).Error; err != nil {
+			return err
+		}
+		if len(sourceIDs) > 0 {
+			if err := tx.Unscoped().
+				Where("platform = ? AND source_id IN ?", name, sourceIDs).
+				Delete(&v3Contest{}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Unscoped().Model(&v3Contest{}).
+			Where("platform = ?", name).
+			UpdateColumn("platform", slug).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Model(&v1ContestRefreshLog{}).
+			Where("platform = ?", name).
+			UpdateColumn("platform", slug).Error; err != nil {
+			return err
+		}
+
+		var exists int64
+		if err := tx.Unscoped().Model(&v4ContestPlatform{}).Where("name = ?", slug).Count(&exists).Error; err != nil {
+			return err
+		}
+		platforms := tx.Unscoped().Where("name = ?", name)
+		if exists > 0 {
+			if err := platforms.Delete(&v4ContestPlatform{}).Error; err != nil {
+				return err
+			}
+		} else if err := platforms.Model(&v4ContestPlatform{}).UpdateColumn("name", slug).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillSearchText 为所有比赛（包括已软删除的）生成搜索文本
+func backfillSearchText(tx *gorm.DB) error {
+	var batch []v3Contest
+	return tx.Unscoped().Select("id", "name").
+		FindInBatches(&batch, 500, func(batchTx *gorm.DB, _ int) error {
+			for _, c := range batch {
+				if err := tx.Unscoped().Model(&v3Contest{}).
+					Where("id = ?", c.Model.ID).
+					UpdateColumn("search_text", search.Text(c.Name)).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
+
+// dedupeContests 每组 (platform, source_id) 只保留最近更新的一条记录，其余记录（包括已软删除的）物理删除
+func dedupeContests(tx *gorm.DB) error {
+	var groups []struct {
+		Platform string
+		SourceID string
+	}
+	if err := tx.Table("contests").
+		Select("platform, source_id").
+		Group("platform, source_id").
+		Having("COUNT(*) > 1").
+		Scan(&groups).Error; err != nil {
+		return err
+	}
+
+	for _, g := range groups {
+		var keep v2Contest
+		if err := tx.Unscoped().
+			Where("platform = ? AND source_id = ?", g.Platform, g.SourceID).
+			Order("deleted_at IS NULL DESC, updated_at DESC, id DESC").
+			First(&keep).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().
+			Where("platform = ? AND source_id = ? AND id <> ?", g.Platform, g.SourceID, keep.Model.ID).
+			Delete(&v2Contest{}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// v1Model 版本 1 的公共字段，快照结构体通过具名字段内嵌（匿名内嵌未导出类型会被 GORM 忽略）
+type v1Model struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+type v1Contest struct {
+	Model           v1Model   `gorm:"embedded"`
+	Name            string    `gorm:"size:255;not null;comment:比赛名称"`
+	Platform        string    `gorm:"size:50;not null;index;comment:比赛平台"`
+	StartTime       time.Time `gorm:"not null;index;comment:开始时间"`
+	EndTime         time.Time `gorm:"not null;index;comment:结束时间"`
+	DurationSeconds int64     `gorm:"not null;comment:持续时间(秒)"`
+	ContestURL      string    `gorm:"size:500;not null;comment:比赛链接"`
+	Status          string    `gorm:"size:20;default:'upcoming';index;comment:比赛状态(upcoming/running/finished)"`
+	SourceID        string    `gorm:"size:100;index;comment:原始平台ID"`
+	LastUpdated     time.Time `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP;comment:最后更新时间"`
+}
+
+func (v1Contest) TableName() string { return "contests" }
+
+// v2Contest 版本 2：source_id 的普通索引替换为 (platform, source_id) 唯一索引
+type v2Contest struct {
+	Model           v1Model   `gorm:"embedded"`
+	Name            string    `gorm:"size:255;not null;comment:比赛名称"`
+	Platform        string    `gorm:"size:50;not null;index;uniqueIndex:idx_contests_platform_source_id,priority:1;comment:比赛平台"`
+	StartTime       time.Time `gorm:"not null;index;comment:开始时间"`
+	EndTime         time.Time `gorm:"not null;index;comment:结束时间"`
+	DurationSeconds int64     `gorm:"not null;comment:持续时间(秒)"`
+	ContestURL      string    `gorm:"size:500;not null;comment:比赛链接"`
+	Status          string    `gorm:"size:20;default:'upcoming';index;comment:比赛状态(upcoming/running/finished)"`
+	SourceID        string    `gorm:"size:100;uniqueIndex:idx_contests_platform_source_id,priority:2;comment:原始平台ID"`
+	LastUpdated     time.Time `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP;comment:最后更新时间"`
+}
+
+func (v2Contest) TableName() string { return "contests" }
+
+// v3Contest 版本 3：添加搜索文本列
+type v3Contest struct {
+	Model           v1Model   `gorm:"embedded"`
+	Name            string    `gorm:"size:255;not null;comment:比赛名称"`
+	Platform        string    `gorm:"size:50;not null;index;uniqueIndex:idx_contests_platform_source_id,priority:1;comment:比赛平台"`
+	StartTime       time.Time `gorm:"not null;index;comment:开始时间"`
+	EndTime         time.Time `gorm:"not null;index;comment:结束时间"`
+	DurationSeconds int64     `gorm:"not null;comment:持续时间(秒)"`
+	ContestURL      string    `gorm:"size:500;not null;comment:比赛链接"`
+	Status          string    `gorm:"size:20;default:'upcoming';index;comment:比赛状态(upcoming/running/finished)"`
+	SourceID        string    `gorm:"size:100;uniqueIndex:idx_contests_platform_source_id,priority:2;comment:原始平台ID"`
+	LastUpdated     time.Time `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP;comment:最后更新时间"`
+	SearchText      string    `gorm:"size:1000;comment:搜索文本(分词、首字母缩写和拼音)"`
+}
+
+func (v3Contest) TableName() string { return "contests" }
+
+type v1ContestPlatform struct {
+	Model          v1Model `gorm:"embedded"`
+	Name           string  `gorm:"size:50;not null;uniqueIndex;comment:平台名称"`
+	DisplayName    string  `gorm:"size:50;not null;comment:显示名称"`
+	APIURL         string  `gorm:"size:500;comment:API地址"`
+	IsActive       bool    `gorm:"default:true;comment:是否激活"`
+	UpdateInterval int     `gorm:"default:3600;comment:更新间隔(秒)"`
+}
+
+func (v1ContestPlatform) TableName() string { return "contest_platforms" }
+
+// v4ContestPlatform 版本 4：添加英文显示名称、主页、图标、品牌色和展示顺序
+type v4ContestPlatform struct {
+	Model          v1Model `gorm:"embedded"`
+	Name           string  `gorm:"size:50;not null;uniqueIndex;comment:平台标识"`
+	DisplayName    string  `gorm:"size:50;not null;comment:显示名称"`
+	DisplayNameEn  string  `gorm:"size:50;comment:英文显示名称"`
+	Homepage       string  `gorm:"size:255;comment:平台主页"`
+	Icon           string  `gorm:"size:500;comment:图标地址"`
+	Color          string  `gorm:"size:20;comment:品牌色"`
+	SortOrder      int     `gorm:"default:0;comment:展示顺序"`
+	APIURL         string  `gorm:"size:500;comment:API地址"`
+	IsActive       bool    `gorm:"default:true;comment:是否激活"`
+	UpdateInterval int     `gorm:"default:3600;comment:更新间隔(秒)"`
+}
+
+func (v4ContestPlatform) TableName() string { return "contest_platforms" }
+
+type v1ContestRefreshLog struct {
+	Model        v1Model `gorm:"embedded"`
+	Platform     string  `gorm:"size:50;not null;index;comment:平台名称"`
+	Status       string  `gorm:"size:20;not null;comment:刷新状态(success/failed)"`
+	Message      string  `gorm:"size:500;comment:刷新消息"`
+	NewCount     int     `gorm:"default:0;comment:新增比赛数量"`
+	UpdatedCount int     `gorm:"default:0;comment:更新比赛数量"`
+	Duration     int64   `gorm:"comment:耗时(毫秒)"`
+	Attempts     int     `gorm:"default:0;comment:尝试次数"`
+	BreakerState string  `gorm:"size:20;comment:熔断器状态(closed/open/half_open)"`
+	RequestID    string  `gorm:"size:64;index;comment:触发刷新的请求ID"`
+}
+
+func (v1ContestRefreshLog) TableName() string { return "contest_refresh_logs" }
+
+type v6ContestRefreshLog struct {
+	Model        v1Model `gorm:"embedded"`
+	Platform     string  `gorm:"size:50;not null;index;comment:平台名称"`
+	Status       string  `gorm:"size:20;not null;comment:刷新状态(success/failed)"`
+	Message      string  `gorm:"size:500;comment:刷新消息"`
+	FetchedCount int     `gorm:"default:0;comment:获取比赛数量"`
+	NewCount     int     `gorm:"default:0;comment:新增比赛数量"`
+	UpdatedCount int     `gorm:"default:0;comment:更新比赛数量"`
+	Duration     int64   `gorm:"comment:耗时(毫秒)"`
+	Attempts     int     `gorm:"default:0;comment:尝试次数"`
+	BreakerState string  `gorm:"size:20;comment:熔断器状态(closed/open/half_open)"`
+	RequestID    string  `gorm:"size:64;index;comment:触发刷新的请求ID"`
+}
+
+func (v6ContestRefreshLog) TableName() string { return "contest_refresh_logs" }
+
+type v5APIKey struct {
+	Model      v1Model    `gorm:"embedded"`
+	Name       string     `gorm:"size:100;not null;comment:名称"`
+	Prefix     string     `gorm:"size:16;not null;comment:Key前缀，用于辨认"`
+	KeyHash    string     `gorm:"size:64;not null;uniqueIndex;comment:Key的SHA-256摘要"`
+	Scopes     string     `gorm:"size:255;not null;default:'';comment:授权范围，逗号分隔"`
+	RateLimit  int        `gorm:"default:0;comment:每分钟请求数上限，0表示使用默认值"`
+	DailyQuota int        `gorm:"default:0;comment:24小时内请求数上限，0表示使用默认值"`
+	ExpiresAt  *time.Time `gorm:"comment:过期时间"`
+	RevokedAt  *time.Time `gorm:"index;comment:吊销时间"`
+	LastUsedAt *time.Time `gorm:"comment:最后使用时间"`
+}
+
+func (v5APIKey) TableName() string { return "api_keys" }
+
+type v5APIKeyUsage struct {
+	ID       uint   `gorm:"primarykey"`
+	APIKeyID uint   `gorm:"not null;uniqueIndex:idx_api_key_usages_key_date,priority:1;comment:API Key ID"`
+	Date     string `gorm:"size:10;not null;uniqueIndex:idx_api_key_usages_key_date,priority:2;comment:日期(YYYY-MM-DD)"`
+	Requests int64  `gorm:"not null;default:0;comment:请求数"`
+	Rejected int64  `gorm:"not null;default:0;comment:因超出配额被拒绝的请求数"`
+}
+
+func (v5APIKeyUsage) TableName() string { return "api_key_usages" }
+
+type v7UserPreference struct {
+	Model     v1Model `gorm:"embedded"`
+	StudentID string  `gorm:"size:64;not null;uniqueIndex;comment:学号"`
+	Timezone  string  `gorm:"size:64;not null;default:'';comment:默认时区，为空表示使用服务端默认时区"`
+}
+
+func (v7UserPreference) TableName() string { return "user_preferences" }
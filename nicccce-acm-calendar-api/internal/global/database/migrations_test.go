@@ -0,0 +1,46 @@
+package database
+
+import (
+	"testing"
+
+	"nicccce-acm-calendar-api/internal/model"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// TestMigrationsMatchModels 执行全部迁移后，当前模型的每个字段都有对应的列
+// 快照结构体以匿名方式内嵌未导出类型时字段会被 GORM 忽略，此测试可发现这类遗漏
+func TestMigrationsMatchModels(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, m := range []any{
+		&model.Contest{},
+		&model.ContestPlatform{},
+		&model.ContestRefreshLog{},
+		&model.APIKey{},
+		&model.APIKeyUsage{},
+		&model.UserPreference{},
+	} {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(m); err != nil {
+			t.Fatal(err)
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			if !db.Migrator().HasColumn(m, field.DBName) {
+				t.Errorf("table %s has no column %s for %s.%s", stmt.Schema.Table, field.DBName, stmt.Schema.Name, field.Name)
+			}
+		}
+	}
+
+}
@@ -0,0 +1,184 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// checkTimeout 单个组件检查的超时时间，避免某个依赖卡住导致探针整体超时
+const checkTimeout = 2 * time.Second
+
+type Status string
+
+const (
+	StatusUp       Status = "up"
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+// Component 单个组件的检查结果
+type Component struct {
+	Status Status `json:"status"`
+	// Critical 为 true 的组件不可用时服务不再就绪
+	Critical bool    `json:"critical"`
+	Latency  float64 `json:"latency_ms"`
+	Error    string  `json:"error,omitempty"`
+	Details  any     `json:"details,omitempty"`
+}
+
+// Report 所有组件的检查结果
+type Report struct {
+	Status     Status               `json:"status"`
+	Components map[string]Component `json:"components"`
+	CheckedAt  time.Time            `json:"checked_at"`
+	// ShuttingDown 服务正在停机，此时不再执行组件检查
+	ShuttingDown bool `json:"shutting_down,omitempty"`
+}
+
+// Ready 未在停机且所有关键组件均可用时服务才算就绪
+func (r *Report) Ready() bool {
+	if r.ShuttingDown {
+		return false
+	}
+	for _, c := range r.Components {
+		if c.Critical && c.Status == StatusDown {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckFunc 检查组件状态，返回附加信息和错误；
+// 需要报告降级状态时返回 *DegradedError
+type CheckFunc func(ctx context.Context) (any, error)
+
+// DegradedError 组件可用但状态异常
+type DegradedError struct {
+	Reason string
+}
+
+func (e *DegradedError) Error() string {
+	return e.Reason
+}
+
+type checker struct {
+	critical bool
+	check    CheckFunc
+}
+
+var (
+	mu       sync.RWMutex
+	checkers = make(map[string]checker)
+
+	startedAt    = time.Now()
+	shuttingDown atomic.Bool
+)
+
+// Liveness 存活探针的结果，只表示进程能处理请求
+type Liveness struct {
+	Status Status  `json:"status"`
+	Uptime float64 `json:"uptime_seconds"`
+}
+
+// Alive 返回进程的存活状态，不检查任何依赖
+func Alive() *Liveness {
+	return &Liveness{Status: StatusUp, Uptime: time.Since(startedAt).Seconds()}
+}
+
+// BeginShutdown 标记服务开始停机，此后 Ready 返回的报告均为未就绪
+func BeginShutdown() {
+	shuttingDown.Store(true)
+}
+
+// Ready 就绪检查：停机后直接返回未就绪，否则执行所有组件检查
+func Ready(ctx context.Context) *Report {
+	if shuttingDown.Load() {
+		return &Report{
+			Status:       StatusDown,
+			Components:   map[string]Component{},
+			CheckedAt:    time.Now(),
+			ShuttingDown: true,
+		}
+	}
+	return Check(ctx)
+}
+
+// Register 注册组件检查，critical 表示该组件不可用时服务不再就绪
+func Register(name string, critical bool, check CheckFunc) {
+	mu.Lock()
+	defer mu.Unlock()
+	checkers[name] = checker{critical: critical, check: check}
+}
+
+// Check 并发执行所有组件检查
+func Check(ctx context.Context) *Report {
+	mu.RLock()
+	names := make([]string, 0, len(checkers))
+	for name := range checkers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	snapshot := make([]checker, len(names))
+	for i, name := range names {
+		snapshot[i] = checkers[name]
+	}
+	mu.RUnlock()
+
+	components := make([]Component, len(names))
+	var wg sync.WaitGroup
+	for i := range snapshot {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			components[i] = run(ctx, snapshot[i])
+		}(i)
+	}
+	wg.Wait()
+
+	report := &Report{
+		Status:     StatusUp,
+		Components: make(map[string]Component, len(names)),
+		CheckedAt:  time.Now(),
+	}
+	for i, name := range names {
+		component := components[i]
+		report.Components[name] = component
+		switch {
+		case component.Status == StatusDown && component.Critical:
+			report.Status = StatusDown
+		case component.Status != StatusUp && report.Status == StatusUp:
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+func run(ctx context.Context, c checker) Component {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := c.check(ctx)
+	component := Component{
+		Status:   StatusUp,
+		Critical: c.critical,
+		Latency:  float64(time.Since(start).Microseconds()) / 1000,
+		Details:  details,
+	}
+
+	var degraded *DegradedError
+	switch {
+	case err == nil:
+	case errors.As(err, &degraded):
+		component.Status = StatusDegraded
+		component.Error = err.Error()
+	default:
+		component.Status = StatusDown
+		component.Error = err.Error()
+	}
+	return component
+}
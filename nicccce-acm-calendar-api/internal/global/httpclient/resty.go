@@ -2,11 +2,18 @@ package httpclient
 
 import (
 	"github.com/go-resty/resty/v2"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"net/http"
 	"time"
 )
 
 var Client *resty.Client
 
 func Init() {
-	Client = resty.New().SetTimeout(10 * time.Second)
+	Client = New().SetTimeout(10 * time.Second)
+}
+
+// New 创建带链路追踪的 HTTP 客户端，每个请求都会生成一个 span 并注入 traceparent
+func New() *resty.Client {
+	return resty.New().SetTransport(otelhttp.NewTransport(http.DefaultTransport))
 }
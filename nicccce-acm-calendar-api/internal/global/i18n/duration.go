@@ -0,0 +1,43 @@
+package i18n
+
+import "time"
+
+// Duration 将时长格式化为可读文本，精确到分钟，最多显示两个相邻的单位
+// 例如 "1天2小时"、"3小时5分钟"、"40分钟"（英文为 "1 day 2 hours"、"3 hours 5 minutes"、"40 minutes"）
+func Duration(lang Lang, d time.Duration) string {
+	if d < time.Minute {
+		return T(lang, "不到1分钟")
+	}
+
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+
+	switch {
+	case days > 0:
+		return join(lang, unit(lang, days, "1天", "%d天"), unit(lang, hours, "1小时", "%d小时"))
+	case hours > 0:
+		return join(lang, unit(lang, hours, "1小时", "%d小时"), unit(lang, minutes, "1分钟", "%d分钟"))
+	default:
+		return unit(lang, minutes, "1分钟", "%d分钟")
+	}
+}
+
+// unit 格式化单个单位，区分单复数，n 为 0 时返回空串
+func unit(lang Lang, n int, one, many string) string {
+	switch n {
+	case 0:
+		return ""
+	case 1:
+		return T(lang, one)
+	default:
+		return T(lang, many, n)
+	}
+}
+
+func join(lang Lang, major, minor string) string {
+	if minor == "" {
+		return major
+	}
+	return T(lang, "%s%s", major, minor)
+}
@@ -0,0 +1,100 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Lang string
+
+const (
+	ZH Lang = "zh"
+	EN Lang = "en"
+
+	// Default 请求未指定或指定了不支持的语言时使用的语言
+	Default = ZH
+)
+
+// ContextKey 是用于在 gin.Context 中存储请求语言的键
+const ContextKey = "lang"
+
+// supported 支持的语言，按优先级排列
+var supported = []Lang{ZH, EN}
+
+// T 翻译消息，msgID 为中文原文（与 gettext 类似，原文即消息ID），没有对应译文时返回原文
+// args 不为空时将译文作为格式串格式化
+func T(lang Lang, msgID string, args ...any) string {
+	msg := msgID
+	if translated, ok := catalogs[lang][msgID]; ok {
+		msg = translated
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// Parse 解析语言标签，如 "en-US"、"zh_CN"，不支持时返回 false
+func Parse(tag string) (Lang, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	primary, _, _ := strings.Cut(strings.ReplaceAll(tag, "_", "-"), "-")
+	for _, lang := range supported {
+		if primary == string(lang) {
+			return lang, true
+		}
+	}
+	return "", false
+}
+
+// FromRequest 获取请求使用的语言：依次为 lang 参数、Accept-Language 请求头和默认语言
+// 经过 Language 中间件的请求直接使用中间件解析的结果
+func FromRequest(c *gin.Context) Lang {
+	if v, ok := c.Get(ContextKey); ok {
+		if lang, ok := v.(Lang); ok {
+			return lang
+		}
+	}
+	if lang, ok := Parse(c.Query("lang")); ok {
+		return lang
+	}
+	if lang, ok := fromAcceptLanguage(c.GetHeader("Accept-Language")); ok {
+		return lang
+	}
+	return Default
+}
+
+// fromAcceptLanguage 按权重选择 Accept-Language 中第一个支持的语言
+// 例如 "en-US,en;q=0.9,zh;q=0.8" 选择 en
+func fromAcceptLanguage(header string) (Lang, bool) {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if tag != "" && q > 0 {
+			candidates = append(candidates, candidate{tag: tag, q: q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	for _, c := range candidates {
+		if lang, ok := Parse(c.tag); ok {
+			return lang, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,77 @@
+package i18n
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFromAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   Lang
+		ok     bool
+	}{
+		{"", "", false},
+		{"en", EN, true},
+		{"zh-CN", ZH, true},
+		{"en-US,en;q=0.9,zh;q=0.8", EN, true},
+		{"zh;q=0.5, en;q=0.8", EN, true},
+		// 权重相同时保持原有顺序
+		{"zh, en", ZH, true},
+		// 跳过不支持的语言和权重为 0 或无法解析的语言
+		{"fr-FR,fr;q=0.9,en;q=0.5", EN, true},
+		{"en;q=0,zh;q=0.1", ZH, true},
+		{"en;q=abc,zh;q=0.1", ZH, true},
+		{"fr, de;q=0.5", "", false},
+		{"*", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			got, ok := fromAcceptLanguage(tt.header)
+			if got != tt.want || ok != tt.ok {
+				t.Fatalf("fromAcceptLanguage(%q) = %q, %v, want %q, %v", tt.header, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		d      time.Duration
+		zh, en string
+	}{
+		{30 * time.Second, "不到1分钟", "less than a minute"},
+		{time.Minute, "1分钟", "1 minute"},
+		{40*time.Minute + 59*time.Second, "40分钟", "40 minutes"},
+		{time.Hour, "1小时", "1 hour"},
+		{3*time.Hour + 5*time.Minute, "3小时5分钟", "3 hours 5 minutes"},
+		{time.Hour + time.Minute, "1小时1分钟", "1 hour 1 minute"},
+		{24 * time.Hour, "1天", "1 day"},
+		// 最多显示两个相邻的单位
+		{26*time.Hour + 30*time.Minute, "1天2小时", "1 day 2 hours"},
+		{3*24*time.Hour + 45*time.Minute, "3天", "3 days"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.d.String(), func(t *testing.T) {
+			if got := Duration(ZH, tt.d); got != tt.zh {
+				t.Fatalf("Duration(zh, %s) = %q, want %q", tt.d, got, tt.zh)
+			}
+			if got := Duration(EN, tt.d); got != tt.en {
+				t.Fatalf("Duration(en, %s) = %q, want %q", tt.d, got, tt.en)
+			}
+		})
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := T(EN, "比赛已删除"); got != "Contest deleted successfully" {
+		t.Fatalf("T(en) = %q", got)
+	}
+	if got := T(ZH, "比赛已删除"); got != "比赛已删除" {
+		t.Fatalf("T(zh) = %q", got)
+	}
+	// 没有译文时返回格式化后的原文
+	if got := T(EN, "未翻译的%d", 1); got != "未翻译的1" {
+		t.Fatalf("T(en, untranslated) = %q", got)
+	}
+}
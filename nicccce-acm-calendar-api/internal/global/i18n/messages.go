@@ -0,0 +1,36 @@
+package i18n
+
+// catalogs 各语言的译文，键为中文原文，因此中文不需要目录
+// 平台名称不在目录中，由平台注册表维护，见 platform.DisplayName
+var catalogs = map[Lang]map[string]string{
+	EN: {
+		// response/code.go 中的错误消息
+		"无效的请求":      "Invalid request",
+		"账号或密码错误":    "Incorrect username or password",
+		"无效的token":   "Invalid token",
+		"权限不足":       "Unauthorized",
+		"无效的API Key": "Invalid API key",
+		"禁止访问":       "Forbidden",
+		"目标不存在":      "Not found",
+		"目标已存在":      "Already exists",
+		"请求过于频繁":     "Too many requests",
+		"服务器内部错误":    "Internal server error",
+		"数据库错误":      "Database error",
+
+		// 比赛倒计时
+		"%s后开始": "starts in %s",
+		"%s后结束": "ends in %s",
+		"已结束":   "finished",
+		"不到1分钟": "less than a minute",
+		"%d天":   "%d days",
+		"1天":    "1 day",
+		"%d小时":  "%d hours",
+		"1小时":   "1 hour",
+		"%d分钟":  "%d minutes",
+		"1分钟":   "1 minute",
+		"%s%s":  "%s %s",
+
+		// 管理接口的提示
+		"比赛已删除": "Contest deleted successfully",
+	},
+}
@@ -40,7 +40,7 @@ func Get() *slog.Logger {
 			handler = slog.NewTextHandler(os.Stdout, opts)
 		}
 
-		instance = slog.New(handler).With(
+		instance = slog.New(contextHandler{handler}).With(
 			"app_name", "nicccce-acm-calendar-api",
 			"env", string(cfg.Mode),
 		)
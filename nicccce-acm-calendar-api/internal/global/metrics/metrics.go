@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+)
+
+const namespace = "acm_calendar"
+
+// HTTP 请求
+var (
+	HTTPRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP 请求总数",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP 请求耗时",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+)
+
+// 爬虫
+var (
+	CrawlerRefreshTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "crawler_refresh_total",
+		Help:      "平台刷新次数，按结果区分",
+	}, []string{"platform", "status"})
+
+	CrawlerRefreshDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "crawler_refresh_duration_seconds",
+		Help:      "平台刷新耗时（含爬取与保存）",
+		Buckets:   []float64{0.5, 1, 2.5, 5, 10, 20, 30, 60, 120},
+	}, []string{"platform", "status"})
+
+	CrawlerContests = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "crawler_contests",
+		Help:      "最近一次成功刷新获取的比赛数量",
+	}, []string{"platform"})
+
+	CrawlerContestsSaved = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "crawler_contests_saved_total",
+		Help:      "刷新保存的比赛数量，按新增/更新区分",
+	}, []string{"platform", "kind"})
+)
+
+// 定时任务
+var (
+	SchedulerJobRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "scheduler_job_runs_total",
+		Help:      "定时任务触发次数，result 为 run（执行）或 skipped（非主节点跳过）",
+	}, []string{"job", "result"})
+
+	SchedulerJobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "scheduler_job_duration_seconds",
+		Help:      "定时任务执行耗时",
+		Buckets:   []float64{0.1, 0.5, 1, 5, 10, 30, 60, 120, 300},
+	}, []string{"job"})
+
+	SchedulerJobLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "scheduler_job_lag_seconds",
+		Help:      "定时任务实际开始时间相对计划时间的延迟",
+	}, []string{"job"})
+
+	SchedulerIsLeader = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "scheduler_is_leader",
+		Help:      "当前实例是否为调度主节点（1 是，0 否）",
+	})
+)
+
+// 限流
+var (
+	RateLimitRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ratelimit_rejections_total",
+		Help:      "被限流拒绝的请求数",
+	}, []string{"limiter"})
+)
+
+// 响应缓存
+var (
+	CacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "响应缓存查询次数，result 为 hit（命中）或 miss（未命中）",
+	}, []string{"result"})
+
+	CacheInvalidations = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_invalidated_tags_total",
+		Help:      "失效的缓存标签数",
+	})
+)
+
+// RegisterDB 注册数据库连接池指标
+func RegisterDB(db *sql.DB, name string) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterRedis 注册 Redis 连接池指标
+func RegisterRedis(client *redis.Client) {
+	prometheus.MustRegister(&redisPoolCollector{client: client})
+}
+
+var (
+	redisHitsDesc     = prometheus.NewDesc(namespace+"_redis_pool_hits_total", "连接池命中次数", nil, nil)
+	redisMissesDesc   = prometheus.NewDesc(namespace+"_redis_pool_misses_total", "连接池未命中次数", nil, nil)
+	redisTimeoutsDesc = prometheus.NewDesc(namespace+"_redis_pool_timeouts_total", "获取连接超时次数", nil, nil)
+	redisTotalDesc    = prometheus.NewDesc(namespace+"_redis_pool_total_conns", "连接总数", nil, nil)
+	redisIdleDesc     = prometheus.NewDesc(namespace+"_redis_pool_idle_conns", "空闲连接数", nil, nil)
+	redisStaleDesc    = prometheus.NewDesc(namespace+"_redis_pool_stale_conns_total", "被移除的过期连接数", nil, nil)
+)
+
+// redisPoolCollector 在每次抓取时读取 go-redis 的连接池统计
+type redisPoolCollector struct {
+	client *redis.Client
+}
+
+func (c *redisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- redisHitsDesc
+	ch <- redisMissesDesc
+	ch <- redisTimeoutsDesc
+	ch <- redisTotalDesc
+	ch <- redisIdleDesc
+	ch <- redisStaleDesc
+}
+
+func (c *redisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.client.PoolStats()
+	ch <- prometheus.MustNewConstMetric(redisHitsDesc, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(redisMissesDesc, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(redisTimeoutsDesc, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(redisTotalDesc, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(redisIdleDesc, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(redisStaleDesc, prometheus.CounterValue, float64(stats.StaleConns))
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"strconv"
+
+	"nicccce-acm-calendar-api/internal/global/apikey"
+	"nicccce-acm-calendar-api/internal/global/ratelimit"
+	"nicccce-acm-calendar-api/internal/global/response"
+	"nicccce-acm-calendar-api/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKey 校验 X-API-Key 请求头，未携带时按匿名请求放行
+// 认证通过后保存 API Key 供限流和授权使用，按 API Key 的配额限流并记录请求统计
+// 需在 RateLimit 之前注册，使按 api_key 维度的路由策略能识别 API Key
+func APIKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw := c.GetHeader(apikey.Header)
+		if raw == "" {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		key, err := apikey.Authenticate(ctx, raw)
+		if err != nil {
+			response.Fail(c, response.ErrServerInternal.WithOrigin(err))
+			return
+		}
+		if key == nil {
+			response.Fail(c, response.ErrAPIKeyInvalid)
+			return
+		}
+		c.Set(apikey.ContextKey, key)
+		c.Set(ratelimit.APIKeyContextKey, strconv.FormatUint(uint64(key.ID), 10))
+
+		for _, policy := range apikey.Quotas(key) {
+			result := ratelimit.Allow(ctx, policy, ratelimit.Subject(c, policy))
+			ratelimit.SetHeaders(c, result)
+			if !result.Allowed {
+				apikey.Record(key.ID, true)
+				response.Fail(c, response.ErrTooManyRequests)
+				return
+			}
+		}
+		apikey.Record(key.ID, false)
+
+		c.Next()
+	}
+}
+
+// Scope 要求请求携带的 API Key 具有授权范围，未携带 API Key 的匿名请求不受影响
+func Scope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key, ok := requestAPIKey(c); ok && !key.HasScope(scope) {
+			response.Fail(c, response.ErrForbidden.WithTips("missing scope "+scope))
+			return
+		}
+		c.Next()
+	}
+}
+
+// AuthOrAPIKey 携带 API Key 时要求其具有授权范围，否则按 Auth(minRoleID) 校验用户 Token
+func AuthOrAPIKey(minRoleID int, scope string) gin.HandlerFunc {
+	auth := Auth(minRoleID)
+	return func(c *gin.Context) {
+		key, ok := requestAPIKey(c)
+		if !ok {
+			auth(c)
+			return
+		}
+		if !key.HasScope(scope) {
+			response.Fail(c, response.ErrForbidden.WithTips("missing scope "+scope))
+			return
+		}
+		c.Next()
+	}
+}
+
+// requestAPIKey 获取 APIKey 中间件认证通过的 API Key
+func requestAPIKey(c *gin.Context) (*model.APIKey, bool) {
+	v, ok := c.Get(apikey.ContextKey)
+	if !ok {
+		return nil, false
+	}
+	key, ok := v.(*model.APIKey)
+	return key, ok
+}
@@ -7,6 +7,19 @@ import (
 	"strings"
 )
 
+// Identify 解析可选的用户 Token，Token 有效时与 Auth 一样保存 payload，否则按匿名请求放行
+// 使不要求登录的接口也能识别用户，如按用户的默认时区输出和按用户限流；需在 RateLimit 之前注册
+func Identify() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+			if payload, valid := jwt.ParseToken(token); valid {
+				c.Set("payload", payload)
+			}
+		}
+		c.Next()
+	}
+}
+
 func Auth(minRoleID int) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 获取 Authorization 头
@@ -1,17 +1,201 @@
 package middleware
 
-import "github.com/gin-gonic/gin"
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
 
+	"nicccce-acm-calendar-api/config"
+	"nicccce-acm-calendar-api/internal/global/apikey"
+	"nicccce-acm-calendar-api/internal/global/ratelimit"
+	"nicccce-acm-calendar-api/internal/global/timezone"
+	"nicccce-acm-calendar-api/tools"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultCorsMaxAge = 600
+
+var (
+	defaultAllowMethods = []string{
+		http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions,
+	}
+	defaultAllowHeaders = []string{
+		"Content-Type", "Authorization", "Accept-Language", "If-None-Match",
+		apikey.Header, RequestIDHeader, timezone.Header,
+	}
+	// exposeHeaders 总是暴露给前端的响应头
+	exposeHeaders = []string{
+		RequestIDHeader, "ETag", "Content-Language", ratelimit.HeaderRetryAfter,
+		ratelimit.HeaderLimit, ratelimit.HeaderRemaining, ratelimit.HeaderReset, ratelimit.HeaderPolicy,
+		"Deprecation", "Link",
+	}
+)
+
+// corsPolicy 由 config.Cors 解析得到的跨域策略
+type corsPolicy struct {
+	allowAll bool
+	origins  map[string]bool
+	// wildcards 带通配符的来源，如 https://*.example.com 拆分为前缀 https:// 和后缀 .example.com
+	wildcards [][2]string
+
+	methods       string
+	methodSet     map[string]bool
+	headers       string
+	reflectHeader bool
+	expose        string
+	credentials   bool
+	maxAge        string
+}
+
+func newCorsPolicy(cfg config.Cors) *corsPolicy {
+	p := &corsPolicy{
+		origins:     make(map[string]bool),
+		methodSet:   make(map[string]bool),
+		credentials: cfg.AllowCredentials,
+	}
+
+	origins := cfg.AllowOrigins
+	if len(origins) == 0 {
+		origins = []string{"*"}
+	}
+	for _, origin := range origins {
+		origin = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(origin), "/"))
+		switch {
+		case origin == "*":
+			p.allowAll = true
+		case strings.Contains(origin, "*"):
+			prefix, suffix, _ := strings.Cut(origin, "*")
+			if !strings.HasSuffix(prefix, "://") || strings.Contains(suffix, "*") {
+				tools.PanicOnErr(fmt.Errorf("invalid cors origin %q: wildcard must be the leftmost subdomain", origin))
+			}
+			p.wildcards = append(p.wildcards, [2]string{prefix, suffix})
+		case origin != "":
+			p.origins[origin] = true
+		}
+	}
+	// 浏览器不接受 Access-Control-Allow-Origin: * 与携带凭据同时出现，反射任意来源又会使任意网站都能以用户身份访问接口
+	if p.allowAll && p.credentials {
+		tools.PanicOnErr(fmt.Errorf("cors: AllowCredentials cannot be used with AllowOrigins \"*\", list the allowed origins explicitly"))
+	}
+
+	methods := cfg.AllowMethods
+	if len(methods) == 0 {
+		methods = defaultAllowMethods
+	}
+	normalized := make([]string, 0, len(methods))
+	for _, method := range methods {
+		method = strings.ToUpper(strings.TrimSpace(method))
+		normalized = append(normalized, method)
+		p.methodSet[method] = true
+	}
+	p.methods = strings.Join(normalized, ", ")
+
+	headers := cfg.AllowHeaders
+	if len(headers) == 0 {
+		headers = defaultAllowHeaders
+	}
+	if len(headers) == 1 && headers[0] == "*" {
+		// 携带凭据时浏览器将 * 视为普通请求头名称，改为反射预检请求中的请求头
+		p.reflectHeader = true
+	}
+	p.headers = strings.Join(headers, ", ")
+	p.expose = strings.Join(append(append([]string{}, exposeHeaders...), cfg.ExposeHeaders...), ", ")
+
+	maxAge := cfg.MaxAge
+	if maxAge == 0 {
+		maxAge = defaultCorsMaxAge
+	}
+	if maxAge > 0 {
+		p.maxAge = strconv.Itoa(maxAge)
+	}
+	return p
+}
+
+func (p *corsPolicy) allowOrigin(origin string) bool {
+	if p.allowAll {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if p.origins[origin] {
+		return true
+	}
+	for _, w := range p.wildcards {
+		prefix, suffix := w[0], w[1]
+		if len(origin) <= len(prefix)+len(suffix) || !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+			continue
+		}
+		if sub := origin[len(prefix) : len(origin)-len(suffix)]; !strings.ContainsAny(sub, "/:@") {
+			return true
+		}
+	}
+	return false
+}
+
+// Cors 按 config.Cors 处理跨域请求
+// 来源匹配时返回该来源（允许所有来源且不携带凭据时返回 *），不匹配时不设置跨域响应头，由浏览器拒绝；
+// 预检请求在此直接响应，来源或方法不被允许时返回 403
 func Cors() gin.HandlerFunc {
+	p := newCorsPolicy(config.Get().Cors)
+
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, POST, PUT, DELETE, OPTIONS, PATCH")
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		h := c.Writer.Header()
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		// 响应随来源变化时需告知缓存
+		if !p.allowAll {
+			h.Add("Vary", "Origin")
+		}
+		if preflight {
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
 			return
 		}
-		c.Next()
+		if !p.allowOrigin(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if p.allowAll {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if p.credentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			h.Set("Access-Control-Expose-Headers", p.expose)
+			c.Next()
+			return
+		}
+
+		if !p.methodSet[strings.ToUpper(c.GetHeader("Access-Control-Request-Method"))] {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		h.Set("Access-Control-Allow-Methods", p.methods)
+		if p.reflectHeader {
+			if requested := c.GetHeader("Access-Control-Request-Headers"); requested != "" {
+				h.Set("Access-Control-Allow-Headers", requested)
+			}
+		} else {
+			h.Set("Access-Control-Allow-Headers", p.headers)
+		}
+		if p.maxAge != "" {
+			h.Set("Access-Control-Max-Age", p.maxAge)
+		}
+		c.AbortWithStatus(http.StatusNoContent)
 	}
 }
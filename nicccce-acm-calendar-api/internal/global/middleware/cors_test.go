@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"testing"
+
+	"nicccce-acm-calendar-api/config"
+)
+
+func TestCorsAllowOrigin(t *testing.T) {
+	p := newCorsPolicy(config.Cors{AllowOrigins: []string{
+		"https://acm.example.com/",
+		"https://*.example.org",
+		"HTTP://*.Example.NET",
+	}})
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		// 精确匹配忽略大小写和配置末尾的 /
+		{"https://acm.example.com", true},
+		{"HTTPS://ACM.EXAMPLE.COM", true},
+		{"http://acm.example.com", false},
+		{"https://acm.example.com:8443", false},
+		{"https://example.com", false},
+		// 通配符匹配任意层级的子域名
+		{"https://a.example.org", true},
+		{"https://a.b.example.org", true},
+		{"http://a.Example.net", true},
+		// 通配符至少匹配一个字符，且不能匹配端口、路径或用户信息
+		{"https://example.org", false},
+		{"https://.example.org", false},
+		{"https://a.example.org:8443", false},
+		{"https://a:1@b.example.org", false},
+		{"https://evil.com/.example.org", false},
+		// 后缀必须完整匹配，不能是另一个域名的一部分
+		{"https://evilexample.org", false},
+		{"https://a.example.org.evil.com", false},
+		{"http://a.example.org", false},
+		{"", false},
+		{"null", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			if got := p.allowOrigin(tt.origin); got != tt.want {
+				t.Fatalf("allowOrigin(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCorsAllowAll(t *testing.T) {
+	for _, origins := range [][]string{nil, {"*"}, {"https://acm.example.com", "*"}} {
+		p := newCorsPolicy(config.Cors{AllowOrigins: origins})
+		if !p.allowAll || !p.allowOrigin("https://anything.test") {
+			t.Fatalf("AllowOrigins %q should allow all origins", origins)
+		}
+	}
+}
+
+func TestCorsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Cors
+	}{
+		{"wildcard not leftmost", config.Cors{AllowOrigins: []string{"https://acm.*.com"}}},
+		{"wildcard without scheme", config.Cors{AllowOrigins: []string{"*.example.com"}}},
+		{"multiple wildcards", config.Cors{AllowOrigins: []string{"https://*.*.example.com"}}},
+		{"credentials with all origins", config.Cors{AllowOrigins: []string{"*"}, AllowCredentials: true}},
+		{"credentials with default origins", config.Cors{AllowCredentials: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("newCorsPolicy(%+v) did not panic", tt.cfg)
+				}
+			}()
+			newCorsPolicy(tt.cfg)
+		})
+	}
+}
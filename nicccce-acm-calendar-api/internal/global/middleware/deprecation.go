@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Deprecated 标记旧路由已废弃，通过 Deprecation 和 Link 响应头告知客户端替代的路径
+// legacyBase 和 successorBase 分别为旧路由和新路由的分组路径，如 /api 和 /api/v1
+func Deprecated(legacyBase, successorBase string) gin.HandlerFunc {
+	legacyBase = strings.TrimSuffix(legacyBase, "/")
+	return func(c *gin.Context) {
+		successor := path.Join(successorBase, strings.TrimPrefix(c.Request.URL.Path, legacyBase))
+		c.Header("Deprecation", "true")
+		c.Header("Link", "<"+successor+`>; rel="successor-version"`)
+		c.Next()
+	}
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"nicccce-acm-calendar-api/internal/global/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Language 解析请求语言并保存到 gin.Context，后续通过 i18n.FromRequest 获取
+// 响应内容随 Accept-Language 变化，需要告知缓存
+func Language() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lang := i18n.FromRequest(c)
+		c.Set(i18n.ContextKey, lang)
+		c.Header("Content-Language", string(lang))
+		c.Header("Vary", "Accept-Language")
+
+		c.Next()
+	}
+}
@@ -18,8 +18,8 @@ func Logger(log *slog.Logger) gin.HandlerFunc {
 		endTime := time.Now()
 		latency := endTime.Sub(startTime)
 
-		// 记录请求日志
-		log.Info("Request",
+		// 记录请求日志，request_id 由 context 附加
+		log.InfoContext(c.Request.Context(), "Request",
 			"method", c.Request.Method,
 			"path", c.Request.URL.Path,
 			"status", c.Writer.Status(),
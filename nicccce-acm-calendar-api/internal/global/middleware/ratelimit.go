@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"nicccce-acm-calendar-api/internal/global/ratelimit"
+	"nicccce-acm-calendar-api/internal/global/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit 按配置的路由策略限流，设置 X-RateLimit-* 响应头，超出限制时响应 429
+func RateLimit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policy := ratelimit.Route(c)
+		if policy == nil {
+			c.Next()
+			return
+		}
+
+		result := ratelimit.Allow(c.Request.Context(), policy, ratelimit.Subject(c, policy))
+		ratelimit.SetHeaders(c, result)
+		if !result.Allowed {
+			response.Fail(c, response.ErrTooManyRequests)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"nicccce-acm-calendar-api/internal/global/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestRateLimitRefreshRoutes 内置策略只限制触发刷新，前端轮询任务进度不会被限流
+func TestRateLimitRefreshRoutes(t *testing.T) {
+	ratelimit.Init()
+
+	r := gin.New()
+	r.Use(RateLimit())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	v1 := r.Group("/v1")
+	v1.POST("/refresh", ok)
+	v1.GET("/refresh/status", ok)
+	v1.GET("/refresh/jobs/:id", ok)
+
+	do := func(method, target string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, target, nil)
+		req.RemoteAddr = "192.0.2.10:1234"
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	policy, _ := ratelimit.Get("refresh")
+	for i := 0; i < policy.Limit; i++ {
+		if code := do(http.MethodPost, "/v1/refresh"); code != http.StatusOK {
+			t.Fatalf("POST /refresh #%d = %d, want 200", i+1, code)
+		}
+	}
+	// 刷新额度用完后仍可继续轮询
+	for i := 0; i < 3*policy.Limit; i++ {
+		if code := do(http.MethodGet, "/v1/refresh/jobs/abc"); code != http.StatusOK {
+			t.Fatalf("GET /refresh/jobs/:id #%d = %d, want 200", i+1, code)
+		}
+		if code := do(http.MethodGet, "/v1/refresh/status"); code != http.StatusOK {
+			t.Fatalf("GET /refresh/status #%d = %d, want 200", i+1, code)
+		}
+	}
+	if code := do(http.MethodPost, "/v1/refresh"); code != http.StatusTooManyRequests {
+		t.Fatalf("POST /refresh over limit = %d, want 429", code)
+	}
+}
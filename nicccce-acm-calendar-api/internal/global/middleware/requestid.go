@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"nicccce-acm-calendar-api/internal/global/logger"
+	"nicccce-acm-calendar-api/tools"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader 请求ID的请求头/响应头
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDContextKey 是用于在 gin.Context 中存储请求ID的键
+const RequestIDContextKey = "request_id"
+
+// validRequestID 限制上游传入的请求ID格式，避免日志注入和超长字段
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 优先沿用网关或调用方传入的请求ID，便于跨服务关联日志
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = tools.RandString(16)
+		}
+
+		c.Set(RequestIDContextKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), requestID))
+
+		c.Next()
+	}
+}
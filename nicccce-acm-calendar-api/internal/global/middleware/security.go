@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"fmt"
+
+	"nicccce-acm-calendar-api/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultContentSecurityPolicy 接口只返回 JSON，禁止加载任何资源和被嵌入页面
+	defaultContentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'"
+	defaultReferrerPolicy        = "strict-origin-when-cross-origin"
+)
+
+// Security 设置常用的安全响应头，返回 HTML 的接口可自行覆盖 Content-Security-Policy
+func Security() gin.HandlerFunc {
+	cfg := config.Get().Security
+	csp := cfg.ContentSecurityPolicy
+	if csp == "" {
+		csp = defaultContentSecurityPolicy
+	}
+	referrer := cfg.ReferrerPolicy
+	if referrer == "" {
+		referrer = defaultReferrerPolicy
+	}
+	var hsts string
+	if cfg.HSTSMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d; includeSubDomains", cfg.HSTSMaxAge)
+	}
+
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("X-Frame-Options", "DENY")
+		h.Set("Content-Security-Policy", csp)
+		h.Set("Referrer-Policy", referrer)
+		if hsts != "" {
+			h.Set("Strict-Transport-Security", hsts)
+		}
+		c.Next()
+	}
+}
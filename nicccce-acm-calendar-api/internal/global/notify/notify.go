@@ -0,0 +1,109 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"nicccce-acm-calendar-api/config"
+	"nicccce-acm-calendar-api/internal/global/httpclient"
+	"nicccce-acm-calendar-api/internal/global/logger"
+)
+
+type Level string
+
+const (
+	LevelInfo     Level = "info"
+	LevelWarning  Level = "warning"
+	LevelCritical Level = "critical"
+)
+
+// Alert 告警内容
+type Alert struct {
+	Level   Level          `json:"level"`
+	Title   string         `json:"title"`
+	Message string         `json:"message"`
+	Source  string         `json:"source"`
+	Fields  map[string]any `json:"fields,omitempty"`
+	Time    time.Time      `json:"time"`
+}
+
+// Channel 告警通知渠道
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, alert Alert) error
+}
+
+var (
+	log      *slog.Logger
+	channels []Channel
+)
+
+// Init 根据配置初始化通知渠道，日志渠道始终启用
+func Init() {
+	log = logger.New("Notify")
+
+	channels = []Channel{logChannel{}}
+	if url := config.Get().Notify.WebhookURL; url != "" {
+		channels = append(channels, webhookChannel{url: url})
+	}
+}
+
+// Send 向所有通知渠道发送告警，单个渠道失败不影响其他渠道
+func Send(ctx context.Context, alert Alert) {
+	if alert.Time.IsZero() {
+		alert.Time = time.Now()
+	}
+	for _, ch := range channels {
+		if err := ch.Send(ctx, alert); err != nil {
+			log.Warn("Failed to send alert", "channel", ch.Name(), "title", alert.Title, "error", err)
+		}
+	}
+}
+
+// logChannel 将告警写入日志
+type logChannel struct{}
+
+func (logChannel) Name() string {
+	return "log"
+}
+
+func (logChannel) Send(_ context.Context, alert Alert) error {
+	level := slog.LevelInfo
+	switch alert.Level {
+	case LevelWarning:
+		level = slog.LevelWarn
+	case LevelCritical:
+		level = slog.LevelError
+	}
+	log.Log(context.Background(), level, alert.Title,
+		"source", alert.Source,
+		"message", alert.Message,
+		"fields", alert.Fields,
+	)
+	return nil
+}
+
+// webhookChannel 以 JSON 形式 POST 告警到配置的 Webhook 地址
+type webhookChannel struct {
+	url string
+}
+
+func (webhookChannel) Name() string {
+	return "webhook"
+}
+
+func (w webhookChannel) Send(ctx context.Context, alert Alert) error {
+	resp, err := httpclient.Client.R().
+		SetContext(ctx).
+		SetBody(alert).
+		Post(w.url)
+	if err != nil {
+		return err
+	}
+	if resp.IsError() {
+		return fmt.Errorf("webhook returned status code: %d", resp.StatusCode())
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package openapi
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"nicccce-acm-calendar-api/internal/global/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// swaggerUI 文档页面使用的 Swagger UI 版本，从 CDN 加载
+const swaggerUI = "https://cdn.jsdelivr.net/npm/swagger-ui-dist@5"
+
+// docsPage 文档页面，%[1]s 为 CDN 地址，%[2]s 为页面标题，%[3]s 为内联脚本的 nonce
+const docsPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>%[2]s</title>
+<link rel="stylesheet" href="%[1]s/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="%[1]s/swagger-ui-bundle.js"></script>
+<script nonce="%[3]s">
+window.ui = SwaggerUIBundle({url: "openapi.json", dom_id: "#swagger-ui", deepLinking: true});
+</script>
+</body>
+</html>
+`
+
+// Handler 提供 OpenAPI 文档和文档页面
+// 文档在第一次请求时生成，此时所有路由都已注册
+type Handler struct {
+	info     Info
+	engine   *gin.Engine
+	basePath string
+	docs     []Route
+
+	once sync.Once
+	spec []byte
+	err  error
+}
+
+// NewHandler 为 engine 中 basePath 下的路由创建文档处理器
+func NewHandler(info Info, engine *gin.Engine, basePath string, docs []Route) *Handler {
+	return &Handler{info: info, engine: engine, basePath: basePath, docs: docs}
+}
+
+// Spec 返回 OpenAPI 文档
+func (h *Handler) Spec(c *gin.Context) {
+	h.once.Do(func() {
+		h.spec, h.err = json.Marshal(Build(h.info, h.basePath, h.engine.Routes(), h.docs))
+	})
+	if h.err != nil {
+		response.Fail(c, response.ErrServerInternal.WithOrigin(h.err))
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", h.spec)
+}
+
+// UI 返回 Swagger UI 文档页面
+// 默认的 Content-Security-Policy 禁止加载任何资源，此处放行 CDN 和带 nonce 的内联脚本
+func (h *Handler) UI(c *gin.Context) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		response.Fail(c, response.ErrServerInternal.WithOrigin(err))
+		return
+	}
+	nonce := base64.StdEncoding.EncodeToString(buf)
+
+	c.Header("Content-Security-Policy", fmt.Sprintf(
+		"default-src 'none'; script-src %[1]s 'nonce-%[2]s'; style-src %[1]s 'unsafe-inline'; "+
+			"img-src 'self' data: %[1]s; connect-src 'self'; frame-ancestors 'none'",
+		"https://cdn.jsdelivr.net", nonce,
+	))
+	c.Data(http.StatusOK, "text/html; charset=utf-8", fmt.Appendf(nil, docsPage, swaggerUI, h.info.Title, nonce))
+}
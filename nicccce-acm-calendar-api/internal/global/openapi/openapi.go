@@ -0,0 +1,386 @@
+package openapi
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"nicccce-acm-calendar-api/internal/global/apikey"
+	"nicccce-acm-calendar-api/internal/global/ratelimit"
+	"nicccce-acm-calendar-api/internal/global/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	securityAPIKey = "ApiKeyAuth"
+	securityBearer = "BearerAuth"
+)
+
+// Param 接口的查询、路径或请求头参数
+type Param struct {
+	Name string
+	// In 参数位置：query、path 或 header，默认为 query
+	In string
+	// Type 参数类型：string、integer 或 boolean，默认为 string
+	Type        string
+	Format      string
+	Required    bool
+	Description string
+	Enum        []string
+}
+
+// Route 一个接口的文档，Method 和 Path 与注册路由时一致，Path 相对于版本分组
+type Route struct {
+	Method      string
+	Path        string
+	Tag         string
+	Summary     string
+	Description string
+	// Params 查询和请求头参数，路径参数未列出时按路由自动生成
+	Params []Param
+	// Body 请求体类型的零值
+	Body any
+	// Data 成功响应中 data 类型的零值
+	Data any
+	// Meta 成功响应中 meta 类型的零值，如分页信息
+	Meta any
+	// Raw 响应不使用 ResponseBody 包装，Data 即为响应体
+	Raw bool
+	// ContentType 成功响应的类型，默认为 application/json
+	ContentType string
+	// Scope 携带 API Key 时需要的授权范围，匿名请求不受影响
+	Scope string
+	// Auth 必须携带管理员 Token 或具有 Scope 授权范围的 API Key
+	Auth bool
+	// Errors 除通用错误外可能返回的 HTTP 状态码，Raw 为 true 时其响应体与成功响应相同
+	Errors []int
+}
+
+// Documented 由模块实现，提供其路由的接口文档
+type Documented interface {
+	Docs() []Route
+}
+
+// Info 文档的基本信息
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Document OpenAPI 3 文档
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Servers    []Server                         `json:"servers"`
+	Tags       []Tag                            `json:"tags,omitempty"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type Tag struct {
+	Name string `json:"name"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Required    bool    `json:"required,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// commonErrors 所有接口都可能返回的错误：API Key 无效、超出速率限制和服务器内部错误
+var commonErrors = []int{http.StatusUnauthorized, http.StatusTooManyRequests, http.StatusInternalServerError}
+
+var anonymousHandler = regexp.MustCompile(`^func\d+$`)
+
+// Build 根据已注册的路由和接口文档生成 OpenAPI 文档
+// 只包含 basePath 下的路由；未编写文档的路由同样列出，保证文档与实际路由一致
+func Build(info Info, basePath string, routes gin.RoutesInfo, docs []Route) *Document {
+	s := newSchemas()
+	envelope := s.of(response.ResponseBody{})
+
+	documented := make(map[string]Route, len(docs))
+	for _, d := range docs {
+		documented[d.Method+" "+d.Path] = d
+	}
+
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info:    info,
+		Servers: []Server{{URL: basePath}},
+		Paths:   make(map[string]map[string]*Operation),
+		Components: Components{
+			Schemas: s.defs,
+			SecuritySchemes: map[string]*SecurityScheme{
+				securityAPIKey: {Type: "apiKey", In: "header", Name: apikey.Header, Description: "第三方调用方的 API Key"},
+				securityBearer: {Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "管理员登录 Token"},
+			},
+		},
+	}
+
+	tags := make(map[string]bool)
+	prefix := strings.TrimSuffix(basePath, "/")
+	for _, ri := range routes {
+		rel, ok := strings.CutPrefix(ri.Path, prefix)
+		if !ok || (rel != "" && !strings.HasPrefix(rel, "/")) {
+			continue
+		}
+		if rel == "" {
+			rel = "/"
+		}
+
+		d, ok := documented[ri.Method+" "+rel]
+		if !ok {
+			d = Route{Method: ri.Method, Path: rel}
+		}
+		op := s.operation(d, ri.Method, ri.Handler, envelope)
+		if d.Tag != "" {
+			tags[d.Tag] = true
+		}
+
+		p := openAPIPath(rel)
+		if doc.Paths[p] == nil {
+			doc.Paths[p] = make(map[string]*Operation)
+		}
+		doc.Paths[p][strings.ToLower(ri.Method)] = op
+	}
+
+	// 标签按模块注册接口文档的顺序排列
+	for _, d := range docs {
+		if tags[d.Tag] {
+			doc.Tags = append(doc.Tags, Tag{Name: d.Tag})
+			delete(tags, d.Tag)
+		}
+	}
+	return doc
+}
+
+func (s *schemas) operation(d Route, method, handler string, envelope *Schema) *Operation {
+	op := &Operation{
+		Summary:     d.Summary,
+		Description: d.Description,
+		OperationID: operationID(method, d.Path, handler),
+		Responses:   make(map[string]*Response),
+	}
+	if d.Tag != "" {
+		op.Tags = []string{d.Tag}
+	}
+
+	// 路径参数按路由生成，文档中有说明时使用文档中的说明
+	params := make(map[string]Param, len(d.Params))
+	for _, p := range d.Params {
+		if p.In == "path" {
+			params[p.Name] = p
+		}
+	}
+	for _, name := range pathParams(d.Path) {
+		p, ok := params[name]
+		if !ok {
+			p = Param{Name: name, In: "path"}
+		}
+		p.Required = true
+		op.Parameters = append(op.Parameters, parameter(p))
+	}
+	for _, p := range d.Params {
+		if p.In != "path" {
+			op.Parameters = append(op.Parameters, parameter(p))
+		}
+	}
+
+	if d.Body != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{"application/json": {Schema: s.of(d.Body)}},
+		}
+	}
+
+	contentType := d.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	body := s.of(d.Data)
+	if !d.Raw {
+		body = wrap(envelope, body, s.of(d.Meta))
+	}
+	op.Responses["200"] = &Response{
+		Description: "成功",
+		Content:     map[string]*MediaType{contentType: {Schema: body}},
+	}
+
+	for _, code := range d.Errors {
+		op.Responses[strconv.Itoa(code)] = errorResponse(code, envelope)
+		if d.Raw {
+			op.Responses[strconv.Itoa(code)].Content = map[string]*MediaType{contentType: {Schema: body}}
+		}
+	}
+	codes := append([]int{}, commonErrors...)
+	if len(op.Parameters) > 0 || op.RequestBody != nil {
+		codes = append(codes, http.StatusBadRequest)
+	}
+	if d.Scope != "" || d.Auth {
+		codes = append(codes, http.StatusForbidden)
+	}
+	for _, code := range codes {
+		op.Responses[strconv.Itoa(code)] = errorResponse(code, envelope)
+	}
+
+	switch {
+	case d.Auth:
+		op.Security = []map[string][]string{{securityBearer: {}}, {securityAPIKey: {}}}
+	case d.Scope != "":
+		// 空的安全要求表示允许匿名访问
+		op.Security = []map[string][]string{{}, {securityAPIKey: {}}}
+	}
+	if d.Scope != "" {
+		note := "携带 API Key 时需要授权范围 `" + d.Scope + "`"
+		if op.Description != "" {
+			note = op.Description + "\n\n" + note
+		}
+		op.Description = note
+	}
+	return op
+}
+
+// wrap 将 data 和 meta 的类型合并到 ResponseBody 中
+func wrap(envelope, data, meta *Schema) *Schema {
+	props := make(map[string]*Schema)
+	if data != nil {
+		props["data"] = data
+	}
+	if meta != nil {
+		props["meta"] = meta
+	}
+	if len(props) == 0 {
+		return envelope
+	}
+	return &Schema{AllOf: []*Schema{envelope, {Type: "object", Properties: props}}}
+}
+
+func errorResponse(code int, envelope *Schema) *Response {
+	r := &Response{
+		Description: http.StatusText(code),
+		Content:     map[string]*MediaType{"application/json": {Schema: envelope}},
+	}
+	if code == http.StatusTooManyRequests {
+		r.Headers = map[string]*Header{
+			ratelimit.HeaderRetryAfter: {Description: "距离可以重试的秒数", Schema: &Schema{Type: "integer"}},
+			ratelimit.HeaderLimit:      {Description: "时间窗口内的请求数上限", Schema: &Schema{Type: "integer"}},
+			ratelimit.HeaderRemaining:  {Description: "时间窗口内剩余的请求数", Schema: &Schema{Type: "integer"}},
+			ratelimit.HeaderReset:      {Description: "时间窗口重置的 Unix 时间戳", Schema: &Schema{Type: "integer"}},
+			ratelimit.HeaderPolicy:     {Description: "触发限制的策略名称", Schema: &Schema{Type: "string"}},
+		}
+	}
+	return r
+}
+
+func parameter(p Param) *Parameter {
+	in := p.In
+	if in == "" {
+		in = "query"
+	}
+	typ := p.Type
+	if typ == "" {
+		typ = "string"
+	}
+	return &Parameter{
+		Name:        p.Name,
+		In:          in,
+		Required:    p.Required,
+		Description: p.Description,
+		Schema:      &Schema{Type: typ, Format: p.Format, Enum: p.Enum},
+	}
+}
+
+// pathParams 按出现顺序返回 gin 路由中的路径参数名
+func pathParams(path string) []string {
+	var names []string
+	for _, seg := range strings.Split(path, "/") {
+		if strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") {
+			names = append(names, seg[1:])
+		}
+	}
+	return names
+}
+
+// openAPIPath 将 gin 路由的 :id 和 *path 转换为 {id} 和 {path}
+func openAPIPath(path string) string {
+	segs := strings.Split(path, "/")
+	for i, seg := range segs {
+		if strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") {
+			segs[i] = "{" + seg[1:] + "}"
+		}
+	}
+	return strings.Join(segs, "/")
+}
+
+// operationID 使用处理函数名，匿名函数按请求方法和路径生成
+func operationID(method, path, handler string) string {
+	name := strings.TrimSuffix(handler, "-fm")
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	if name != "" && !anonymousHandler.MatchString(name) {
+		return name
+	}
+
+	id := strings.ToLower(method)
+	for _, seg := range strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == '-' || r == ':' || r == '*'
+	}) {
+		id += strings.ToUpper(seg[:1]) + seg[1:]
+	}
+	return id
+}
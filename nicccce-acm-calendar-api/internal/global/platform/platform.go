@@ -0,0 +1,122 @@
+package platform
+
+import (
+	"strings"
+
+	"nicccce-acm-calendar-api/internal/global/i18n"
+)
+
+// 各平台的标识，数据库、缓存键、接口参数中统一使用标识表示平台
+const (
+	Codeforces = "codeforces"
+	AtCoder    = "atcoder"
+	LeetCode   = "leetcode"
+	NowCoder   = "nowcoder"
+	Luogu      = "luogu"
+)
+
+// Platform 平台信息
+type Platform struct {
+	// Slug 平台标识，小写字母
+	Slug string
+	// Names 各语言的显示名称，缺少某语言时使用默认语言的名称
+	Names map[i18n.Lang]string
+	// Aliases 平台的其他名称，查找平台时与标识和显示名称一样可用
+	Aliases  []string
+	Homepage string
+	Icon     string
+	// Color 品牌色，形如 "#1F8ACB"
+	Color string
+}
+
+// registry 所有支持的平台，按展示顺序排列
+var registry = []*Platform{
+	{
+		Slug:     Codeforces,
+		Names:    map[i18n.Lang]string{i18n.ZH: "Codeforces", i18n.EN: "Codeforces"},
+		Aliases:  []string{"CF"},
+		Homepage: "https://codeforces.com",
+		Icon:     "https://codeforces.com/favicon.png",
+		Color:    "#1F8ACB",
+	},
+	{
+		Slug:     AtCoder,
+		Names:    map[i18n.Lang]string{i18n.ZH: "AtCoder", i18n.EN: "AtCoder"},
+		Homepage: "https://atcoder.jp",
+		Icon:     "https://atcoder.jp/favicon.ico",
+		Color:    "#222222",
+	},
+	{
+		Slug:     LeetCode,
+		Names:    map[i18n.Lang]string{i18n.ZH: "力扣", i18n.EN: "LeetCode"},
+		Aliases:  []string{"LC", "leetcode-cn"},
+		Homepage: "https://leetcode.com",
+		Icon:     "https://leetcode.com/favicon.ico",
+		Color:    "#FFA116",
+	},
+	{
+		Slug:     NowCoder,
+		Names:    map[i18n.Lang]string{i18n.ZH: "牛客", i18n.EN: "NowCoder"},
+		Aliases:  []string{"牛客网"},
+		Homepage: "https://ac.nowcoder.com",
+		Icon:     "https://static.nowcoder.com/images/logo_87_87.png",
+		Color:    "#25BB9B",
+	},
+	{
+		Slug:     Luogu,
+		Names:    map[i18n.Lang]string{i18n.ZH: "洛谷", i18n.EN: "Luogu"},
+		Homepage: "https://www.luogu.com.cn",
+		Icon:     "https://www.luogu.com.cn/favicon.ico",
+		Color:    "#3498DB",
+	},
+}
+
+// lookup 小写的标识、显示名称和别名到平台的索引
+var lookup = func() map[string]*Platform {
+	m := make(map[string]*Platform)
+	for _, p := range registry {
+		m[p.Slug] = p
+		for _, name := range p.Names {
+			m[strings.ToLower(name)] = p
+		}
+		for _, alias := range p.Aliases {
+			m[strings.ToLower(alias)] = p
+		}
+	}
+	return m
+}()
+
+// All 所有支持的平台，按展示顺序排列
+func All() []*Platform {
+	return registry
+}
+
+// Lookup 按标识、任一语言的显示名称或别名查找平台，不区分大小写
+func Lookup(name string) (*Platform, bool) {
+	p, ok := lookup[strings.ToLower(strings.TrimSpace(name))]
+	return p, ok
+}
+
+// Normalize 将平台名称转换为标识，未知平台原样返回
+func Normalize(name string) string {
+	if p, ok := Lookup(name); ok {
+		return p.Slug
+	}
+	return name
+}
+
+// Name 平台在 lang 下的显示名称
+func (p *Platform) Name(lang i18n.Lang) string {
+	if name, ok := p.Names[lang]; ok {
+		return name
+	}
+	return p.Names[i18n.Default]
+}
+
+// DisplayName 标识为 slug 的平台在 lang 下的显示名称，未知平台返回 slug
+func DisplayName(slug string, lang i18n.Lang) string {
+	if p, ok := Lookup(slug); ok {
+		return p.Name(lang)
+	}
+	return slug
+}
@@ -0,0 +1,101 @@
+package ratelimit
+
+import (
+	"path"
+	"strconv"
+	"strings"
+
+	"nicccce-acm-calendar-api/config"
+	"nicccce-acm-calendar-api/internal/global/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyContextKey 是 API Key 认证通过后在 gin.Context 中保存 API Key 标识的键
+const APIKeyContextKey = "api_key_id"
+
+// 限流相关响应头
+const (
+	HeaderLimit      = "X-RateLimit-Limit"
+	HeaderRemaining  = "X-RateLimit-Remaining"
+	HeaderReset      = "X-RateLimit-Reset"
+	HeaderPolicy     = "X-RateLimit-Policy"
+	HeaderRetryAfter = "Retry-After"
+)
+
+// Subject 按策略的限流维度获取请求的主体
+// 按用户限流时使用 middleware.Identify 或 middleware.Auth 保存的用户，没有用户 Token 时使用 API Key；
+// 按 API Key 限流时使用 API Key；均不存在时按客户端 IP 限流
+func Subject(c *gin.Context, policy *Policy) string {
+	switch policy.By {
+	case ByUser:
+		if v, ok := c.Get("payload"); ok {
+			if claims, ok := v.(*jwt.Claims); ok && claims.StudentID != "" {
+				return "user:" + claims.StudentID
+			}
+		}
+		fallthrough
+	case ByAPIKey:
+		if id := c.GetString(APIKeyContextKey); id != "" {
+			return "key:" + id
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// Route 返回请求路由对应的策略，未配置限流时返回 nil
+// 路由按相对于 API 前缀和版本号的路由模板匹配，同一策略对各版本的接口同时生效；须在路由匹配后调用
+func Route(c *gin.Context) *Policy {
+	fullPath := c.FullPath()
+	if !enabled || fullPath == "" {
+		return nil
+	}
+	base := path.Clean("/" + config.Get().Prefix)
+	if base != "/" {
+		if fullPath != base && !strings.HasPrefix(fullPath, base+"/") {
+			return nil
+		}
+		fullPath = strings.TrimPrefix(fullPath, base)
+		if fullPath == "" {
+			fullPath = "/"
+		}
+	}
+	return policyFor(c.Request.Method, stripVersion(fullPath))
+}
+
+// stripVersion 去掉路径开头的版本号，如 /v1/refresh 返回 /refresh
+func stripVersion(fullPath string) string {
+	rest, ok := strings.CutPrefix(fullPath, "/v")
+	if !ok {
+		return fullPath
+	}
+	end := strings.IndexByte(rest, '/')
+	if end < 0 {
+		end = len(rest)
+	}
+	if end == 0 || strings.Trim(rest[:end], "0123456789") != "" {
+		return fullPath
+	}
+	if rest = rest[end:]; rest == "" {
+		return "/"
+	}
+	return rest
+}
+
+// SetHeaders 设置 X-RateLimit-* 响应头，请求被拒绝时同时设置 Retry-After
+// 同一请求经过多个策略时保留剩余额度最少的一组
+func SetHeaders(c *gin.Context, r *Result) {
+	h := c.Writer.Header()
+	if prev := h.Get(HeaderRemaining); prev != "" && r.Allowed {
+		if n, err := strconv.Atoi(prev); err == nil && n <= r.Remaining {
+			return
+		}
+	}
+	h.Set(HeaderLimit, strconv.Itoa(r.Policy.Limit))
+	h.Set(HeaderRemaining, strconv.Itoa(r.Remaining))
+	h.Set(HeaderReset, strconv.FormatInt(r.Reset.Unix(), 10))
+	h.Set(HeaderPolicy, strconv.Itoa(r.Policy.Limit)+";w="+strconv.Itoa(int(r.Policy.Window.Seconds())))
+	if !r.Allowed {
+		h.Set(HeaderRetryAfter, strconv.Itoa(r.RetryAfterSeconds()))
+	}
+}
@@ -0,0 +1,111 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"nicccce-acm-calendar-api/internal/global/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestStripVersion(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"/v1/refresh", "/refresh"},
+		{"/v12/contests/:id", "/contests/:id"},
+		{"/v1", "/"},
+		{"/v1/", "/"},
+		{"/refresh", "/refresh"},
+		// 不是版本号的路径段保持不变
+		{"/v/refresh", "/v/refresh"},
+		{"/v1beta/refresh", "/v1beta/refresh"},
+		{"/version", "/version"},
+		{"/", "/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := stripVersion(tt.in); got != tt.want {
+				t.Fatalf("stripVersion(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoute(t *testing.T) {
+	setRoutes(t, map[string]string{"/refresh/*": "refresh"})
+	prevEnabled := enabled
+	enabled = true
+	t.Cleanup(func() { enabled = prevEnabled })
+
+	var got *Policy
+	r := gin.New()
+	r.Use(func(c *gin.Context) { got = Route(c) })
+	noop := func(*gin.Context) {}
+	r.POST("/v1/refresh/:platform", noop)
+	r.POST("/refresh/:platform", noop)
+	r.GET("/v1/contests", noop)
+
+	tests := []struct {
+		method, target string
+		want           string
+	}{
+		{http.MethodPost, "/v1/refresh/atcoder", "refresh"},
+		// 未带版本号的旧路径与 v1 使用相同的策略
+		{http.MethodPost, "/refresh/atcoder", "refresh"},
+		{http.MethodGet, "/v1/contests", ""},
+		// 未匹配到路由时不限流
+		{http.MethodGet, "/v1/unknown", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			got = nil
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.target, nil))
+			name := ""
+			if got != nil {
+				name = got.Name
+			}
+			if name != tt.want {
+				t.Fatalf("Route = %q, want %q", name, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubject(t *testing.T) {
+	user := &jwt.Claims{Payload: jwt.Payload{StudentID: "2024001"}}
+
+	tests := []struct {
+		name    string
+		by      string
+		payload *jwt.Claims
+		apiKey  string
+		want    string
+	}{
+		{"ip", ByIP, user, "3", "ip:192.0.2.1"},
+		{"user", ByUser, user, "3", "user:2024001"},
+		// 没有用户 Token 时按 API Key，二者都没有时按 IP
+		{"user without token", ByUser, nil, "3", "key:3"},
+		{"anonymous user", ByUser, nil, "", "ip:192.0.2.1"},
+		{"api key", ByAPIKey, user, "3", "key:3"},
+		{"api key without key", ByAPIKey, user, "", "ip:192.0.2.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			c.Request.RemoteAddr = "192.0.2.1:1234"
+			if tt.payload != nil {
+				c.Set("payload", tt.payload)
+			}
+			if tt.apiKey != "" {
+				c.Set(APIKeyContextKey, tt.apiKey)
+			}
+			if got := Subject(c, &Policy{By: tt.by}); got != tt.want {
+				t.Fatalf("Subject = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
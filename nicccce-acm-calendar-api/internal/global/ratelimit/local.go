@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// localWindow 进程内的滑动窗口限流，用于未配置 Redis 或 Redis 不可用的情况
+// 多实例部署时各实例分别计数
+type localWindow struct {
+	mu   sync.Mutex
+	logs map[string]*requestLog
+
+	janitorOnce sync.Once
+}
+
+// requestLog 窗口内各次请求的时间，按时间升序
+type requestLog struct {
+	times  []time.Time
+	window time.Duration
+}
+
+func newLocalWindow() *localWindow {
+	return &localWindow{logs: make(map[string]*requestLog)}
+}
+
+// startJanitor 在后台定期清理，重复调用只启动一次
+func (w *localWindow) startJanitor() {
+	w.janitorOnce.Do(func() {
+		go w.janitor()
+	})
+}
+
+func (w *localWindow) check(policy *Policy, key string, peek bool, now time.Time) *Result {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	l, ok := w.logs[key]
+	if !ok {
+		l = &requestLog{window: policy.Window}
+	}
+	l.trim(now)
+
+	allowed := len(l.times) < policy.Limit
+	if allowed && !peek {
+		l.times = append(l.times, now)
+		w.logs[key] = l
+	}
+
+	oldest := now
+	if len(l.times) > 0 {
+		oldest = l.times[0]
+	}
+	return newResult(policy, allowed, len(l.times), oldest, now)
+}
+
+// trim 移除窗口外的请求
+func (l *requestLog) trim(now time.Time) {
+	cutoff := now.Add(-l.window)
+	i := 0
+	for i < len(l.times) && !l.times[i].After(cutoff) {
+		i++
+	}
+	l.times = l.times[i:]
+}
+
+// janitor 定期清理窗口内已没有请求的主体
+func (w *localWindow) janitor() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		w.sweep(now)
+	}
+}
+
+// sweep 移除窗口内已没有请求的主体
+func (w *localWindow) sweep(now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for key, l := range w.logs {
+		l.trim(now)
+		if len(l.times) == 0 {
+			delete(w.logs, key)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLocalWindow(t *testing.T) {
+	w := newLocalWindow()
+	policy := &Policy{Name: "test", Limit: 2, Window: time.Minute}
+	start := time.Date(2024, 11, 20, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		name      string
+		at        time.Duration
+		peek      bool
+		allowed   bool
+		remaining int
+	}{
+		{"first", 0, false, true, 1},
+		{"peek does not count", 10 * time.Second, true, true, 1},
+		{"second", 20 * time.Second, false, true, 0},
+		{"over limit", 30 * time.Second, false, false, 0},
+		// 第一次请求移出窗口后恢复一次额度
+		{"after first expires", time.Minute + time.Second, false, true, 0},
+		{"over limit again", time.Minute + 2*time.Second, false, false, 0},
+	}
+	for _, s := range steps {
+		r := w.check(policy, "ip:a", s.peek, start.Add(s.at))
+		if r.Allowed != s.allowed || r.Remaining != s.remaining {
+			t.Fatalf("%s: allowed = %v, remaining = %d, want %v, %d", s.name, r.Allowed, r.Remaining, s.allowed, s.remaining)
+		}
+	}
+
+	// 被拒绝时需等待窗口内最早的请求（20 秒时）移出窗口
+	r := w.check(policy, "ip:a", false, start.Add(70*time.Second))
+	if want := start.Add(80 * time.Second); !r.Reset.Equal(want) || r.RetryAfter != 10*time.Second {
+		t.Fatalf("reset = %s, retry after = %s, want %s, 10s", r.Reset, r.RetryAfter, want)
+	}
+
+	// 各主体分别计数
+	if r := w.check(policy, "ip:b", false, start.Add(70*time.Second)); !r.Allowed || r.Remaining != 1 {
+		t.Fatalf("other subject: allowed = %v, remaining = %d, want true, 1", r.Allowed, r.Remaining)
+	}
+}
+
+func TestLocalWindowSweep(t *testing.T) {
+	w := newLocalWindow()
+	policy := &Policy{Name: "test", Limit: 5, Window: time.Minute}
+	start := time.Date(2024, 11, 20, 12, 0, 0, 0, time.UTC)
+
+	w.check(policy, "ip:a", false, start)
+	w.check(policy, "ip:b", false, start.Add(30*time.Second))
+	// 只查询的主体不会被记录
+	w.check(policy, "ip:c", true, start)
+
+	w.sweep(start.Add(time.Minute + time.Second))
+	if _, ok := w.logs["ip:a"]; ok {
+		t.Fatal("ip:a should be removed after its requests expire")
+	}
+	if _, ok := w.logs["ip:b"]; !ok {
+		t.Fatal("ip:b still has requests in the window and should be kept")
+	}
+	if len(w.logs) != 1 {
+		t.Fatalf("len(logs) = %d, want 1", len(w.logs))
+	}
+}
@@ -0,0 +1,255 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"nicccce-acm-calendar-api/config"
+	"nicccce-acm-calendar-api/internal/global/logger"
+	"nicccce-acm-calendar-api/internal/global/metrics"
+	redisclient "nicccce-acm-calendar-api/internal/global/redis"
+	"nicccce-acm-calendar-api/tools"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const keyPrefix = "ratelimit:"
+
+// 限流维度
+const (
+	ByIP     = "ip"
+	ByUser   = "user"
+	ByAPIKey = "api_key"
+)
+
+// Policy 限流策略：同一维度下每个主体在任意长度为 Window 的时间段内最多 Limit 次请求
+type Policy struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+	// By 限流维度，ip、user 或 api_key；请求没有对应的用户或 API Key 时按 IP 限流
+	By string
+}
+
+// Result 一次限流检查的结果
+type Result struct {
+	Policy    *Policy
+	Allowed   bool
+	Remaining int
+	// Reset 窗口内最早的请求移出窗口的时间，此时至少恢复一次请求额度
+	Reset time.Time
+	// RetryAfter 被拒绝时需要等待的时间
+	RetryAfter time.Duration
+}
+
+// defaultPolicies 内置策略，可通过配置覆盖
+var defaultPolicies = map[string]Policy{
+	// 刷新相关接口
+	"refresh": {Limit: 5, Window: time.Minute, By: ByIP},
+	// 创建刷新任务，每个刷新范围（全部平台或单个平台）单独计数
+	"refresh_job": {Limit: 10, Window: 10 * time.Minute, By: ByUser},
+	// API Key 的默认配额，可按 Key 单独设置上限，见 apikey.Quotas
+	"api_key":       {Limit: 60, Window: time.Minute, By: ByAPIKey},
+	"api_key_daily": {Limit: 10000, Window: 24 * time.Hour, By: ByAPIKey},
+}
+
+// defaultRoutes 内置的路由到策略的映射，可通过配置覆盖
+// 只限制触发刷新的 POST 请求，查询刷新状态和轮询任务进度的 GET 请求不限流
+var defaultRoutes = map[string]string{
+	"POST /refresh":   "refresh",
+	"POST /refresh/*": "refresh",
+}
+
+var (
+	log      *slog.Logger
+	enabled  bool
+	policies map[string]*Policy
+	routes   []route
+
+	// local 未配置 Redis 或 Redis 不可用时使用的进程内限流
+	local = newLocalWindow()
+	// degraded Redis 不可用、正在使用进程内限流
+	degraded atomic.Bool
+)
+
+// slidingWindow 原子地执行滑动窗口限流
+// 使用有序集合记录窗口内每次请求的时间（微秒），成员带随机后缀，同一时刻的多次请求分别计数
+// 时间取自 Redis 服务器，各实例的时钟偏差不影响结果
+// KEYS[1] 限流键；ARGV[1] 窗口长度（微秒），ARGV[2] 上限，ARGV[3] 成员后缀，ARGV[4] 为 1 时只查询不计数
+// 返回 {是否允许, 窗口内请求数, 窗口内最早请求的时间（微秒）, 当前时间（微秒）}
+var slidingWindow = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+	allowed = 1
+	if ARGV[4] ~= '1' then
+		redis.call('ZADD', KEYS[1], now, now .. '-' .. ARGV[3])
+		count = count + 1
+		redis.call('PEXPIRE', KEYS[1], math.ceil(window / 1000))
+	end
+end
+
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+local first = now
+if oldest[2] then
+	first = tonumber(oldest[2])
+end
+return {allowed, count, first, now}
+`)
+
+// Init 加载限流策略和路由配置，并启动进程内限流的定期清理
+func Init() {
+	log = logger.New("RateLimit")
+	cfg := config.Get().RateLimit
+	enabled = !cfg.Disabled
+	if enabled {
+		local.startJanitor()
+	}
+
+	policies = make(map[string]*Policy, len(defaultPolicies)+len(cfg.Policies))
+	for name, p := range defaultPolicies {
+		p.Name = name
+		policies[name] = &p
+	}
+	for name, c := range cfg.Policies {
+		name = strings.ToLower(name)
+		p := Policy{Name: name, Limit: c.Limit, Window: time.Duration(c.Window) * time.Second, By: strings.ToLower(c.By)}
+		if base, ok := policies[name]; ok {
+			// 只覆盖配置了的字段
+			if p.Limit == 0 {
+				p.Limit = base.Limit
+			}
+			if p.Window == 0 {
+				p.Window = base.Window
+			}
+			if p.By == "" {
+				p.By = base.By
+			}
+		}
+		if p.By == "" {
+			p.By = ByIP
+		}
+		if p.Limit <= 0 || p.Window <= 0 {
+			tools.PanicOnErr(fmt.Errorf("invalid rate limit policy %s: limit and window must be positive", name))
+		}
+		if p.By != ByIP && p.By != ByUser && p.By != ByAPIKey {
+			tools.PanicOnErr(fmt.Errorf("invalid rate limit policy %s: unsupported by %q", name, p.By))
+		}
+		policies[name] = &p
+	}
+
+	merged := make(map[string]string, len(defaultRoutes)+len(cfg.Routes))
+	for pattern, name := range defaultRoutes {
+		merged[pattern] = name
+	}
+	for pattern, name := range cfg.Routes {
+		merged[pattern] = strings.ToLower(name)
+	}
+	routes = routes[:0]
+	for pattern, name := range merged {
+		r, err := parseRoute(pattern, name)
+		tools.PanicOnErr(err)
+		if r.policy != "" {
+			if _, ok := policies[r.policy]; !ok {
+				tools.PanicOnErr(fmt.Errorf("rate limit route %q uses unknown policy %s", pattern, name))
+			}
+		}
+		routes = append(routes, r)
+	}
+	sortRoutes(routes)
+}
+
+// Get 获取策略，策略不存在时返回 false
+func Get(name string) (*Policy, bool) {
+	p, ok := policies[name]
+	return p, ok
+}
+
+// Allow 按策略为主体计数一次请求并返回是否允许；限流关闭时总是允许
+// Redis 不可用时使用进程内计数，不会返回错误
+func Allow(ctx context.Context, policy *Policy, subject string) *Result {
+	return check(ctx, policy, subject, false)
+}
+
+// Peek 查询主体在策略下的当前额度，不计数
+func Peek(ctx context.Context, policy *Policy, subject string) *Result {
+	return check(ctx, policy, subject, true)
+}
+
+func check(ctx context.Context, policy *Policy, subject string, peek bool) *Result {
+	if !enabled {
+		return &Result{Policy: policy, Allowed: true, Remaining: policy.Limit, Reset: time.Now()}
+	}
+
+	key := keyPrefix + policy.Name + ":" + subject
+	var result *Result
+	if redisclient.Enabled() {
+		var err error
+		result, err = checkRedis(ctx, policy, key, peek)
+		if err != nil {
+			// Redis 不可用时退化为进程内限流，各实例分别计数
+			if degraded.CompareAndSwap(false, true) {
+				log.WarnContext(ctx, "Redis is unavailable, falling back to in-memory rate limiting", "error", err)
+			}
+			result = local.check(policy, key, peek, time.Now())
+		} else if degraded.CompareAndSwap(true, false) {
+			log.InfoContext(ctx, "Redis is available again, resuming distributed rate limiting")
+		}
+	} else {
+		result = local.check(policy, key, peek, time.Now())
+	}
+
+	if !result.Allowed && !peek {
+		metrics.RateLimitRejections.WithLabelValues(policy.Name).Inc()
+	}
+	return result
+}
+
+func checkRedis(ctx context.Context, policy *Policy, key string, peek bool) (*Result, error) {
+	peekArg := "0"
+	if peek {
+		peekArg = "1"
+	}
+	values, err := slidingWindow.Run(ctx, redisclient.RedisClient, []string{key},
+		policy.Window.Microseconds(), policy.Limit, tools.RandString(8), peekArg).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 4 {
+		return nil, fmt.Errorf("unexpected rate limit script result: %v", values)
+	}
+
+	now := time.UnixMicro(values[3])
+	oldest := time.UnixMicro(values[2])
+	return newResult(policy, values[0] == 1, int(values[1]), oldest, now), nil
+}
+
+// newResult 由窗口内的请求数和最早请求时间计算结果
+func newResult(policy *Policy, allowed bool, count int, oldest, now time.Time) *Result {
+	result := &Result{
+		Policy:    policy,
+		Allowed:   allowed,
+		Remaining: max(policy.Limit-count, 0),
+		Reset:     oldest.Add(policy.Window),
+	}
+	if !allowed {
+		result.RetryAfter = max(result.Reset.Sub(now), 0)
+	}
+	return result
+}
+
+// RetryAfterSeconds Retry-After 响应头的值，向上取整且至少为 1 秒
+func (r *Result) RetryAfterSeconds() int {
+	return max(int(math.Ceil(r.RetryAfter.Seconds())), 1)
+}
@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// route 路由到限流策略的映射
+// 配置格式为 "METHOD /path" 或 "/path"，路径相对于 API 前缀并使用路由模板（如 /contests/:id），
+// 以 "/*" 结尾时匹配该路径及其所有子路径；策略名为 "none" 时表示该路由不限流
+type route struct {
+	method string
+	path   string
+	prefix bool
+	policy string
+}
+
+// policyNone 表示不限流，用于在配置中排除内置规则覆盖的路由
+const policyNone = "none"
+
+func parseRoute(pattern, policy string) (route, error) {
+	r := route{policy: policy}
+	if policy == policyNone {
+		r.policy = ""
+	}
+
+	fields := strings.Fields(pattern)
+	switch len(fields) {
+	case 1:
+		r.path = fields[0]
+	case 2:
+		// 配置文件中的 map 键会被转换为小写
+		r.method = strings.ToUpper(fields[0])
+		r.path = fields[1]
+	default:
+		return r, fmt.Errorf("invalid rate limit route %q", pattern)
+	}
+	if r.method != "" && !validMethod(r.method) {
+		return r, fmt.Errorf("invalid rate limit route %q: unknown method %s", pattern, r.method)
+	}
+	if !strings.HasPrefix(r.path, "/") {
+		return r, fmt.Errorf("invalid rate limit route %q: path must start with /", pattern)
+	}
+	if strings.HasSuffix(r.path, "/*") {
+		r.prefix = true
+		r.path = strings.TrimSuffix(r.path, "/*")
+	}
+	return r, nil
+}
+
+func validMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// sortRoutes 按优先级排序：精确匹配优先于前缀匹配，路径更长的优先，指定方法的优先
+func sortRoutes(routes []route) {
+	sort.SliceStable(routes, func(i, j int) bool {
+		a, b := routes[i], routes[j]
+		if a.prefix != b.prefix {
+			return !a.prefix
+		}
+		if len(a.path) != len(b.path) {
+			return len(a.path) > len(b.path)
+		}
+		if (a.method == "") != (b.method == "") {
+			return a.method != ""
+		}
+		return a.path < b.path
+	})
+}
+
+func (r *route) match(method, path string) bool {
+	if r.method != "" && r.method != method {
+		return false
+	}
+	if !r.prefix {
+		return path == r.path
+	}
+	return path == r.path || strings.HasPrefix(path, r.path+"/")
+}
+
+// policyFor 返回路由对应的策略，未配置或配置为 none 时返回 nil
+func policyFor(method, path string) *Policy {
+	for i := range routes {
+		if routes[i].match(method, path) {
+			if routes[i].policy == "" {
+				return nil
+			}
+			return policies[routes[i].policy]
+		}
+	}
+	return nil
+}
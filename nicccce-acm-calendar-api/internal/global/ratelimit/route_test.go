@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// setRoutes 使用给定的路由配置替换当前的策略和路由
+func setRoutes(t *testing.T, config map[string]string) {
+	t.Helper()
+	prevPolicies, prevRoutes := policies, routes
+	t.Cleanup(func() { policies, routes = prevPolicies, prevRoutes })
+
+	policies = map[string]*Policy{
+		"refresh": {Name: "refresh", Limit: 5, Window: time.Minute, By: ByIP},
+		"search":  {Name: "search", Limit: 60, Window: time.Minute, By: ByIP},
+		"admin":   {Name: "admin", Limit: 10, Window: time.Minute, By: ByUser},
+	}
+	routes = nil
+	for pattern, policy := range config {
+		r, err := parseRoute(pattern, policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		routes = append(routes, r)
+	}
+	sortRoutes(routes)
+}
+
+func TestParseRouteInvalid(t *testing.T) {
+	for _, pattern := range []string{"", "refresh", "GET refresh", "FETCH /refresh", "GET /refresh extra"} {
+		t.Run(pattern, func(t *testing.T) {
+			if _, err := parseRoute(pattern, "refresh"); err == nil {
+				t.Fatalf("parseRoute(%q) succeeded, want error", pattern)
+			}
+		})
+	}
+}
+
+func TestPolicyFor(t *testing.T) {
+	setRoutes(t, map[string]string{
+		"/refresh":             "refresh",
+		"/refresh/*":           "refresh",
+		"get /refresh/status":  "none",
+		"GET /contests/search": "search",
+		"/admin/*":             "admin",
+		"/admin/contests/*":    "refresh",
+	})
+
+	tests := []struct {
+		method, path string
+		want         string
+	}{
+		{http.MethodPost, "/refresh", "refresh"},
+		{http.MethodPost, "/refresh/:platform", "refresh"},
+		// 精确匹配优先于前缀匹配，配置为 none 时不限流
+		{http.MethodGet, "/refresh/status", ""},
+		{http.MethodPost, "/refresh/status", "refresh"},
+		// 指定方法的路由只匹配该方法
+		{http.MethodGet, "/contests/search", "search"},
+		{http.MethodPost, "/contests/search", ""},
+		// 前缀匹配只匹配完整的路径段，更长的前缀优先
+		{http.MethodGet, "/refreshes", ""},
+		{http.MethodGet, "/admin", "admin"},
+		{http.MethodGet, "/admin/api-keys", "admin"},
+		{http.MethodDelete, "/admin/contests/:id", "refresh"},
+		{http.MethodGet, "/contests", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			got := ""
+			if p := policyFor(tt.method, tt.path); p != nil {
+				got = p.Name
+			}
+			if got != tt.want {
+				t.Fatalf("policyFor(%s, %s) = %q, want %q", tt.method, tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"nicccce-acm-calendar-api/tools"
+)
+
+// Lock 基于 Redis 的分布式锁
+// 每把锁持有随机令牌，续期和释放时校验令牌，避免操作已被其他持有者获取的锁；
+// 未配置 Redis 时退化为进程内的锁
+type Lock struct {
+	key   string
+	token string
+	ttl   time.Duration
+}
+
+func NewLock(key string, ttl time.Duration) *Lock {
+	return &Lock{
+		key:   key,
+		token: tools.RandString(32),
+		ttl:   ttl,
+	}
+}
+
+// Acquire 尝试获取锁，不阻塞
+func (l *Lock) Acquire(ctx context.Context) (bool, error) {
+	return SetNX(ctx, l.key, l.token, l.ttl)
+}
+
+// Refresh 续期锁，返回 false 表示锁已丢失
+func (l *Lock) Refresh(ctx context.Context) (bool, error) {
+	return CompareAndExpire(ctx, l.key, l.token, l.ttl)
+}
+
+// Release 释放锁
+func (l *Lock) Release(ctx context.Context) error {
+	_, err := CompareAndDelete(ctx, l.key, l.token)
+	return err
+}
+
+// KeepAlive 在后台按 ttl/3 的间隔续期锁，直到 ctx 结束或锁丢失
+// 返回的 channel 在锁丢失时关闭
+func (l *Lock) KeepAlive(ctx context.Context) <-chan struct{} {
+	lost := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(l.ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if ok, err := l.Refresh(ctx); err == nil && !ok {
+					close(lost)
+					return
+				}
+			}
+		}
+	}()
+	return lost
+}
@@ -0,0 +1,133 @@
+package redis
+
+import (
+	"sync"
+	"time"
+)
+
+// Local 未配置 Redis 时使用的进程内存储，提供与 Redis 字符串命令对应的最小子集
+// 仅在单实例部署时语义正确
+var Local = NewMemoryStore()
+
+type memoryItem struct {
+	value    string
+	expireAt time.Time // 零值表示永不过期
+}
+
+func (i memoryItem) expired(now time.Time) bool {
+	return !i.expireAt.IsZero() && now.After(i.expireAt)
+}
+
+// MemoryStore 带过期时间的内存键值存储
+// 过期的键在访问时惰性删除，另有后台协程定期清理未再被访问的键
+type MemoryStore struct {
+	mu    sync.Mutex
+	items map[string]memoryItem
+}
+
+func NewMemoryStore() *MemoryStore {
+	s := &MemoryStore{items: make(map[string]memoryItem)}
+	go s.janitor()
+	return s
+}
+
+// Get 获取键的值，第二个返回值表示键是否存在
+func (s *MemoryStore) Get(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.load(key)
+	return item.value, ok
+}
+
+// Set 设置键的值，ttl 为 0 表示永不过期
+func (s *MemoryStore) Set(key, value string, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items[key] = newMemoryItem(value, ttl)
+}
+
+// SetNX 仅当键不存在时设置，返回是否设置成功
+func (s *MemoryStore) SetNX(key, value string, ttl time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.load(key); ok {
+		return false
+	}
+	s.items[key] = newMemoryItem(value, ttl)
+	return true
+}
+
+// Delete 删除键
+func (s *MemoryStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, key)
+}
+
+// CompareAndDelete 仅当键的值等于 value 时删除，返回是否删除成功
+func (s *MemoryStore) CompareAndDelete(key, value string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if item, ok := s.load(key); !ok || item.value != value {
+		return false
+	}
+	delete(s.items, key)
+	return true
+}
+
+// CompareAndExpire 仅当键的值等于 value 时重设过期时间，返回是否设置成功
+func (s *MemoryStore) CompareAndExpire(key, value string, ttl time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if item, ok := s.load(key); !ok || item.value != value {
+		return false
+	}
+	s.items[key] = newMemoryItem(value, ttl)
+	return true
+}
+
+// CompareAndSwap 仅当键的值等于 old 时替换为 value 并重设过期时间，返回是否替换成功
+func (s *MemoryStore) CompareAndSwap(key, old, value string, ttl time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if item, ok := s.load(key); !ok || item.value != old {
+		return false
+	}
+	s.items[key] = newMemoryItem(value, ttl)
+	return true
+}
+
+// load 读取未过期的键，调用方需持有锁
+func (s *MemoryStore) load(key string) (memoryItem, bool) {
+	item, ok := s.items[key]
+	if !ok {
+		return memoryItem{}, false
+	}
+	if item.expired(time.Now()) {
+		delete(s.items, key)
+		return memoryItem{}, false
+	}
+	return item, true
+}
+
+func (s *MemoryStore) janitor() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		s.mu.Lock()
+		for key, item := range s.items {
+			if item.expired(now) {
+				delete(s.items, key)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+func newMemoryItem(value string, ttl time.Duration) memoryItem {
+	item := memoryItem{value: value}
+	if ttl > 0 {
+		item.expireAt = time.Now().Add(ttl)
+	}
+	return item
+}
@@ -3,28 +3,36 @@ package redis
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"nicccce-acm-calendar-api/config"
+	"nicccce-acm-calendar-api/internal/global/logger"
 	"nicccce-acm-calendar-api/tools"
 
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 )
 
-// RedisClient 定义全局 Redis 客户端实例
+// RedisClient 定义全局 Redis 客户端实例，未配置 Redis 时为 nil
 var RedisClient *redis.Client
 
-// customLogger 实现 log.Logger 接口，用于日志输出
+// customLogger 实现 go-redis 的 internal.Logging 接口，将客户端内部日志接入 slog
 type customLogger struct {
 	enabled bool
+	log     *slog.Logger
 }
 
 func (l customLogger) Printf(ctx context.Context, format string, v ...interface{}) {
 	if l.enabled {
-		fmt.Printf(format, v...)
-		fmt.Println() // 添加换行
+		l.log.InfoContext(ctx, fmt.Sprintf(format, v...))
 	}
 }
 
 func Init() {
+	// 未配置地址时不连接 Redis，各功能改用进程内实现
+	if config.Get().Redis.Host == "" {
+		return
+	}
+
 	// 构建 Redis 连接地址
 	addr := fmt.Sprintf("%s:%s",
 		config.Get().Redis.Host,
@@ -39,22 +47,42 @@ func Init() {
 	}
 
 	// 根据运行模式设置日志
-	var logger customLogger
+	log := logger.New("Redis")
+	redisLogger := customLogger{log: log}
 	switch config.Get().Mode {
 	case config.ModeDebug:
-		logger = customLogger{enabled: true} // 启用日志
+		redisLogger.enabled = true // 启用日志
 	case config.ModeRelease:
-		logger = customLogger{enabled: false} // 禁用日志
+		redisLogger.enabled = false // 禁用日志
 	}
-	redis.SetLogger(logger)
+	redis.SetLogger(redisLogger)
 
 	// 初始化 Redis 客户端
 	redisClient := redis.NewClient(redisOptions)
 	ctx := context.Background()
 
-	// 测试连接
+	// 连接失败时不阻止启动，与未配置 Redis 时一样改用进程内实现
 	if err := redisClient.Ping(ctx).Err(); err != nil {
-		tools.PanicOnErr(err)
+		log.Error("Failed to connect to Redis, falling back to in-memory implementations", "addr", addr, "error", err)
+		_ = redisClient.Close()
+		return
 	}
+
+	// 为每条命令生成 span
+	tools.PanicOnErr(redisotel.InstrumentTracing(redisClient))
 	RedisClient = redisClient
 }
+
+// Enabled 是否启用了 Redis；未配置或启动时无法连接时为 false，
+// 此时 Get、Set 等函数改用进程内实现，服务只能单实例部署
+func Enabled() bool {
+	return RedisClient != nil
+}
+
+// Close 关闭 Redis 客户端
+func Close() error {
+	if RedisClient == nil {
+		return nil
+	}
+	return RedisClient.Close()
+}
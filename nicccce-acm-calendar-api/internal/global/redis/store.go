@@ -0,0 +1,121 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// compareAndDeleteScript 仅当键的值与预期一致时才删除
+var compareAndDeleteScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// compareAndExpireScript 仅当键的值与预期一致时才续期
+var compareAndExpireScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// compareAndSwapScript 仅当键的值与预期一致时才替换为新值
+var compareAndSwapScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+	return 1
+end
+return 0
+`)
+
+// 以下函数封装各模块共用的 Redis 字符串和哈希命令，未启用 Redis 时统一改用 Local，
+// 调用方无需自行判断 Enabled；键不存在时与 go-redis 一致返回 redis.Nil
+
+// Get 获取键的值
+func Get(ctx context.Context, key string) (string, error) {
+	if !Enabled() {
+		value, ok := Local.Get(key)
+		if !ok {
+			return "", redis.Nil
+		}
+		return value, nil
+	}
+	return RedisClient.Get(ctx, key).Result()
+}
+
+// Set 设置键的值，ttl 为 0 表示永不过期
+func Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	if !Enabled() {
+		Local.Set(key, value, ttl)
+		return nil
+	}
+	return RedisClient.Set(ctx, key, value, ttl).Err()
+}
+
+// SetNX 仅当键不存在时设置，返回是否设置成功
+func SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	if !Enabled() {
+		return Local.SetNX(key, value, ttl), nil
+	}
+	return RedisClient.SetNX(ctx, key, value, ttl).Result()
+}
+
+// Del 删除键
+func Del(ctx context.Context, key string) error {
+	if !Enabled() {
+		Local.Delete(key)
+		return nil
+	}
+	return RedisClient.Del(ctx, key).Err()
+}
+
+// HGet 获取哈希字段的值，进程内实现以 key:field 作为键
+func HGet(ctx context.Context, key, field string) (string, error) {
+	if !Enabled() {
+		return Get(ctx, key+":"+field)
+	}
+	return RedisClient.HGet(ctx, key, field).Result()
+}
+
+// HSet 设置哈希字段的值
+func HSet(ctx context.Context, key, field, value string) error {
+	if !Enabled() {
+		return Set(ctx, key+":"+field, value, 0)
+	}
+	return RedisClient.HSet(ctx, key, field, value).Err()
+}
+
+// CompareAndDelete 仅当 key 的值等于 value 时删除 key，返回是否删除成功
+func CompareAndDelete(ctx context.Context, key, value string) (bool, error) {
+	if !Enabled() {
+		return Local.CompareAndDelete(key, value), nil
+	}
+	n, err := compareAndDeleteScript.Run(ctx, RedisClient, []string{key}, value).Int()
+	return n == 1, err
+}
+
+// CompareAndExpire 仅当 key 的值等于 value 时重设过期时间，返回是否设置成功
+func CompareAndExpire(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	if !Enabled() {
+		return Local.CompareAndExpire(key, value, ttl), nil
+	}
+	n, err := compareAndExpireScript.Run(ctx, RedisClient, []string{key}, value, ttl.Milliseconds()).Int()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	return n == 1, err
+}
+
+// CompareAndSwap 仅当 key 的值等于 old 时将其替换为 value 并重设过期时间，返回是否替换成功
+func CompareAndSwap(ctx context.Context, key, old, value string, ttl time.Duration) (bool, error) {
+	if !Enabled() {
+		return Local.CompareAndSwap(key, old, value, ttl), nil
+	}
+	n, err := compareAndSwapScript.Run(ctx, RedisClient, []string{key}, old, value, ttl.Milliseconds()).Int()
+	return n == 1, err
+}
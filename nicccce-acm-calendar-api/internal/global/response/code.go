@@ -16,7 +16,8 @@ var (
 
 // 401 Unauthorized
 var (
-	ErrUnauthorized = newError(http.StatusUnauthorized, "权限不足") // 401 Unauthorized
+	ErrUnauthorized  = newError(http.StatusUnauthorized, "权限不足")      // 401 Unauthorized
+	ErrAPIKeyInvalid = newError(http.StatusUnauthorized, "无效的API Key") // 401 Unauthorized
 )
 
 // 403 Forbidden
@@ -34,6 +35,11 @@ var (
 	ErrAlreadyExists = newError(http.StatusConflict, "目标已存在") // 409 Conflict
 )
 
+// 429 Too Many Requests
+var (
+	ErrTooManyRequests = newError(http.StatusTooManyRequests, "请求过于频繁") // 429 Too Many Requests
+)
+
 // 500 Internal Server Error
 var (
 	ErrServerInternal = newError(http.StatusInternalServerError, "服务器内部错误") // 500 Internal Server Error
@@ -3,6 +3,8 @@ package response
 import (
 	"errors"
 	"fmt"
+
+	"nicccce-acm-calendar-api/internal/global/i18n"
 )
 
 // ErrorContextKey 是用于在 gin.Context 中存储错误对象的键
@@ -12,12 +14,18 @@ type Error struct {
 	Code    int32  `json:"code"`
 	Message string `json:"msg"`
 	Origin  string `json:"origin"`
+
+	// msgID 消息原文，返回给客户端时按请求语言翻译，见 i18n.T
+	msgID string
+	// tips 追加在消息之后的提示信息，不翻译
+	tips string
 }
 
 func newError(code int32, msg string) *Error {
 	return &Error{
 		Code:    code,
 		Message: msg,
+		msgID:   msg,
 	}
 }
 
@@ -40,13 +48,26 @@ func (e *Error) WithOrigin(err error) *Error {
 		Code:    e.Code,
 		Message: e.Message,
 		Origin:  fmt.Sprintf("%+v", err),
+		msgID:   e.msgID,
+		tips:    e.tips,
 	}
 }
 
 // WithTips 向前端返回额外的提示信息（config.ReleaseMode 也可见）
 func (e *Error) WithTips(details ...string) *Error {
+	tips := " " + fmt.Sprintf("%v", details)
 	return &Error{
 		Code:    e.Code,
-		Message: e.Message + " " + fmt.Sprintf("%v", details),
+		Message: e.Message + tips,
+		msgID:   e.msgID,
+		tips:    e.tips + tips,
+	}
+}
+
+// Localize 按语言翻译错误消息，提示信息保持原样
+func (e *Error) Localize(lang i18n.Lang) string {
+	if e.msgID == "" {
+		return e.Message
 	}
+	return i18n.T(lang, e.msgID) + e.tips
 }
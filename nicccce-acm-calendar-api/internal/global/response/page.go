@@ -0,0 +1,15 @@
+package response
+
+// PageMeta 列表接口的分页信息
+type PageMeta struct {
+	// Total 满足过滤条件的总数，与分页参数无关
+	Total int64 `json:"total"`
+	// Page 当前页码，使用游标分页时为 0
+	Page     int `json:"page,omitempty"`
+	PageSize int `json:"page_size"`
+	// Sort 实际使用的排序方式
+	Sort    string `json:"sort"`
+	HasMore bool   `json:"has_more"`
+	// NextCursor 获取下一页的游标，没有下一页时为空
+	NextCursor string `json:"next_cursor,omitempty"`
+}
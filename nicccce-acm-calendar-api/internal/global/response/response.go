@@ -3,6 +3,7 @@ package response
 import (
 	"fmt"
 	"nicccce-acm-calendar-api/config"
+	"nicccce-acm-calendar-api/internal/global/i18n"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -19,6 +20,8 @@ type ResponseBody struct {
 	Origin string `json:"origin,omitempty"`
 	// Data 是响应的数据内容，对于成功响应包含实际数据，错误响应通常为 null
 	Data any `json:"data,omitempty"`
+	// Meta 是响应的附加信息，例如列表接口的分页信息
+	Meta any `json:"meta,omitempty"`
 	// Timestamp 响应时间戳
 	Timestamp int64 `json:"timestamp,omitempty"`
 }
@@ -40,6 +43,21 @@ func Success(c *gin.Context, data ...any) {
 	c.JSON(200, response)
 }
 
+// SuccessWithMeta 发送带附加信息的成功响应，状态码为 200
+// 参数:
+//   - c: gin 上下文，用于发送响应
+//   - data: 数据内容，设置为 ResponseBody.Data
+//   - meta: 附加信息，设置为 ResponseBody.Meta
+func SuccessWithMeta(c *gin.Context, data any, meta any) {
+	c.JSON(200, ResponseBody{
+		Code:      success.Code,
+		Msg:       success.Message,
+		Data:      data,
+		Meta:      meta,
+		Timestamp: time.Now().Unix(),
+	})
+}
+
 // Fail 发送错误 HTTP 响应，并终止请求处理
 // 参数:
 //   - c: gin 上下文，用于发送响应
@@ -57,7 +75,7 @@ func Fail(c *gin.Context, err error) {
 
 	// 设置响应状态码和消息
 	response.Code = e.Code
-	response.Msg = e.Message
+	response.Msg = e.Localize(i18n.FromRequest(c))
 	response.Timestamp = time.Now().Unix()
 
 	// 在 debug 模式下，添加错误来源信息
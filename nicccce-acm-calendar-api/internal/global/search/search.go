@@ -0,0 +1,343 @@
+package search
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+// MaxTextLength 搜索文本的最大长度（字符数），与数据库中 search_text 列的长度一致
+const MaxTextLength = 1000
+
+// 各种匹配方式的得分，越精确得分越高
+const (
+	scoreExact     = 10
+	scoreHanWord   = 8
+	scoreAcronym   = 8
+	scorePrefix    = 7
+	scorePinyin    = 6
+	scoreInitials  = 5
+	scoreSubstring = 4
+	scoreFuzzy     = 3
+)
+
+var pinyinArgs = pinyin.NewArgs()
+
+// Range 名称中匹配部分的字符区间 [Start, End)，按 rune 计
+type Range struct {
+	Start int
+	End   int
+}
+
+// Result 名称与搜索词的匹配结果
+type Result struct {
+	// Score 相关度得分，为 0 时表示没有任何搜索词匹配
+	Score float64
+	// Matched 匹配上的搜索词数量
+	Matched int
+	Ranges  []Range
+}
+
+// word 名称中的一个词：连续的字母、连续的数字或连续的汉字
+type word struct {
+	text  string // 小写
+	start int
+	end   int
+	han   bool
+	// syllables 汉字词中每个字的拼音
+	syllables []string
+}
+
+// Tokenize 将文本切分为小写的搜索词，字母与数字之间、汉字与其他字符之间都会切开
+// 例如 "ABC380" 切分为 ["abc", "380"]
+func Tokenize(s string) []string {
+	words := splitWords([]rune(s))
+	tokens := make([]string, 0, len(words))
+	for _, w := range words {
+		tokens = append(tokens, w.text)
+	}
+	return tokens
+}
+
+// Text 生成用于数据库预筛选的搜索文本：名称中的词、英文词的首字母缩写，以及汉字的全拼和拼音首字母
+// 例如 "AtCoder Beginner Contest 380" 生成 "atcoder beginner contest 380 abc"
+func Text(name string) string {
+	words := splitWords([]rune(name))
+	parts := make([]string, 0, len(words)+3)
+	for _, w := range words {
+		parts = append(parts, w.text)
+	}
+	if acronym, _ := acronymOf(words); len(acronym) > 1 {
+		parts = append(parts, acronym)
+	}
+	for _, w := range words {
+		if w.han && len(w.syllables) > 0 {
+			parts = append(parts, strings.Join(w.syllables, ""), initialsOf(w.syllables))
+		}
+	}
+
+	text := []rune(strings.Join(parts, " "))
+	if len(text) > MaxTextLength {
+		text = text[:MaxTextLength]
+	}
+	return string(text)
+}
+
+// Match 计算名称与搜索词的相关度，tokens 应由 Tokenize 生成
+// 每个搜索词取最精确的一种匹配方式计分，总分按匹配上的搜索词比例折算，部分匹配的结果排名靠后
+func Match(name string, tokens []string) Result {
+	var result Result
+	if len(tokens) == 0 {
+		return result
+	}
+
+	words := splitWords([]rune(name))
+	acronym, acronymPos := acronymOf(words)
+
+	var total float64
+	for _, token := range tokens {
+		score, ranges := matchToken(words, acronym, acronymPos, token)
+		if score == 0 {
+			continue
+		}
+		total += score
+		result.Matched++
+		result.Ranges = append(result.Ranges, ranges...)
+	}
+	result.Score = total * float64(result.Matched) / float64(len(tokens))
+	result.Ranges = mergeRanges(result.Ranges)
+	return result
+}
+
+// Highlight 对名称做 HTML 转义，并用 <em> 标记匹配部分
+func Highlight(name string, ranges []Range) string {
+	runes := []rune(name)
+	var b strings.Builder
+	prev := 0
+	for _, r := range ranges {
+		if r.Start < prev || r.End > len(runes) {
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[prev:r.Start])))
+		b.WriteString("<em>")
+		b.WriteString(html.EscapeString(string(runes[r.Start:r.End])))
+		b.WriteString("</em>")
+		prev = r.End
+	}
+	b.WriteString(html.EscapeString(string(runes[prev:])))
+	return b.String()
+}
+
+func matchToken(words []word, acronym string, acronymPos []int, token string) (float64, []Range) {
+	var (
+		best   float64
+		ranges []Range
+	)
+	consider := func(score float64, r ...Range) {
+		if score > best {
+			best, ranges = score, r
+		}
+	}
+	tokenLen := len([]rune(token))
+
+	for _, w := range words {
+		switch {
+		case w.text == token:
+			consider(scoreExact, Range{w.start, w.end})
+		case strings.HasPrefix(w.text, token):
+			consider(scorePrefix, Range{w.start, w.start + tokenLen})
+		case w.han && strings.Contains(w.text, token):
+			offset := len([]rune(w.text[:strings.Index(w.text, token)]))
+			consider(scoreHanWord, Range{w.start + offset, w.start + offset + tokenLen})
+		case strings.Contains(w.text, token):
+			offset := len([]rune(w.text[:strings.Index(w.text, token)]))
+			consider(scoreSubstring, Range{w.start + offset, w.start + offset + tokenLen})
+		}
+
+		if w.han {
+			if from, to, ok := matchPinyin(w.syllables, token); ok {
+				consider(scorePinyin, Range{w.start + from, w.start + to})
+			}
+			if i := strings.Index(initialsOf(w.syllables), token); i >= 0 && tokenLen > 1 {
+				consider(scoreInitials, Range{w.start + i, w.start + i + tokenLen})
+			}
+		} else if tokenLen >= 4 && len(w.text) >= 4 && withinDistance(w.text, token, maxDistance(tokenLen)) {
+			consider(scoreFuzzy, Range{w.start, w.end})
+		}
+	}
+
+	// 缩写至少两个字母才计分，避免单个字母命中大量比赛
+	if i := strings.Index(acronym, token); i >= 0 && tokenLen > 1 {
+		score := float64(scoreAcronym)
+		if i > 0 {
+			score = scorePinyin
+		}
+		r := make([]Range, 0, tokenLen)
+		for _, pos := range acronymPos[i : i+tokenLen] {
+			r = append(r, Range{pos, pos + 1})
+		}
+		consider(score, r...)
+	}
+
+	return best, ranges
+}
+
+// matchPinyin 判断 token 是否为某个字开始的连续全拼（最后一个字可以只写前缀），返回对应的字区间
+func matchPinyin(syllables []string, token string) (int, int, bool) {
+	for i := range syllables {
+		rest := token
+		for j := i; j < len(syllables) && rest != ""; j++ {
+			s := syllables[j]
+			if strings.HasPrefix(rest, s) {
+				rest = rest[len(s):]
+				if rest == "" {
+					return i, j + 1, true
+				}
+				continue
+			}
+			// 末尾的字只写了拼音前缀，至少需要写完第一个字
+			if j > i && strings.HasPrefix(s, rest) {
+				return i, j + 1, true
+			}
+			break
+		}
+	}
+	return 0, 0, false
+}
+
+func splitWords(runes []rune) []word {
+	var words []word
+	kind := func(r rune) int {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			return 1
+		case unicode.IsLetter(r):
+			return 2
+		case unicode.IsDigit(r):
+			return 3
+		default:
+			return 0
+		}
+	}
+
+	for i := 0; i < len(runes); {
+		k := kind(runes[i])
+		if k == 0 {
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(runes) && kind(runes[j]) == k {
+			j++
+		}
+
+		w := word{start: i, end: j, han: k == 1}
+		lower := make([]rune, 0, j-i)
+		for _, r := range runes[i:j] {
+			lower = append(lower, unicode.ToLower(r))
+			if w.han {
+				if py := pinyin.SinglePinyin(r, pinyinArgs); len(py) > 0 {
+					w.syllables = append(w.syllables, py[0])
+				} else {
+					w.syllables = append(w.syllables, "")
+				}
+			}
+		}
+		w.text = string(lower)
+		words = append(words, w)
+		i = j
+	}
+	return words
+}
+
+// acronymOf 英文词的首字母缩写及每个字母在名称中的位置
+func acronymOf(words []word) (string, []int) {
+	var (
+		b   strings.Builder
+		pos []int
+	)
+	for _, w := range words {
+		if w.han || !unicode.IsLetter([]rune(w.text)[0]) {
+			continue
+		}
+		r := []rune(w.text)[0]
+		if r >= unicode.MaxASCII {
+			continue
+		}
+		b.WriteRune(r)
+		pos = append(pos, w.start)
+	}
+	return b.String(), pos
+}
+
+func initialsOf(syllables []string) string {
+	var b strings.Builder
+	for _, s := range syllables {
+		if s == "" {
+			// 没有拼音的字占位，保证首字母与字的位置一一对应
+			b.WriteByte(' ')
+			continue
+		}
+		b.WriteByte(s[0])
+	}
+	return b.String()
+}
+
+func maxDistance(n int) int {
+	if n >= 8 {
+		return 2
+	}
+	return 1
+}
+
+// withinDistance 判断两个字符串的编辑距离是否不超过 max
+func withinDistance(a, b string, max int) bool {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > max || -d > max {
+		return false
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > max {
+			return false
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)] <= max
+}
+
+// mergeRanges 排序并合并重叠的区间
+func mergeRanges(ranges []Range) []Range {
+	if len(ranges) == 0 {
+		return nil
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Start < ranges[j].Start })
+	merged := []Range{ranges[0]}
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r.Start <= last.End {
+			last.End = max(last.End, r.End)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
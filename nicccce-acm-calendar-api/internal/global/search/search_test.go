@@ -0,0 +1,140 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", []string{}},
+		{"  ", []string{}},
+		{"ABC380", []string{"abc", "380"}},
+		{"AtCoder Beginner Contest", []string{"atcoder", "beginner", "contest"}},
+		{"牛客周赛Round 5", []string{"牛客周赛", "round", "5"}},
+		{"Div.2 (Rated)", []string{"div", "2", "rated"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := Tokenize(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Tokenize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	const abc = "AtCoder Beginner Contest 380"
+
+	tests := []struct {
+		name    string
+		text    string
+		query   string
+		score   float64
+		matched int
+		ranges  []Range
+	}{
+		{"exact word", abc, "380", scoreExact, 1, []Range{{25, 28}}},
+		{"prefix", abc, "begin", scorePrefix, 1, []Range{{8, 13}}},
+		{"substring", abc, "coder", scoreSubstring, 1, []Range{{2, 7}}},
+		{"acronym", abc, "abc", scoreAcronym, 1, []Range{{0, 1}, {8, 9}, {17, 18}}},
+		{"typo", abc, "begniner", scoreFuzzy, 1, []Range{{8, 16}}},
+		{"all tokens", abc, "abc 380", scoreAcronym + scoreExact, 2, []Range{{0, 1}, {8, 9}, {17, 18}, {25, 28}}},
+		// 只匹配一半的搜索词，得分按比例折算
+		{"partial", abc, "abc 999", scoreAcronym * 0.5, 1, []Range{{0, 1}, {8, 9}, {17, 18}}},
+		{"no match", abc, "leetcode", 0, 0, nil},
+		{"han word", "牛客周赛", "周赛", scoreHanWord, 1, []Range{{2, 4}}},
+		{"pinyin", "牛客周赛", "zhousai", scorePinyin, 1, []Range{{2, 4}}},
+		{"pinyin prefix", "牛客周赛", "niuk", scorePinyin, 1, []Range{{0, 2}}},
+		{"pinyin initials", "牛客周赛", "nkzs", scoreInitials, 1, []Range{{0, 4}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Match(tt.text, Tokenize(tt.query))
+			if got.Score != tt.score || got.Matched != tt.matched || !reflect.DeepEqual(got.Ranges, tt.ranges) {
+				t.Fatalf("Match(%q, %q) = %+v, want {Score:%v Matched:%d Ranges:%v}",
+					tt.text, tt.query, got, tt.score, tt.matched, tt.ranges)
+			}
+		})
+	}
+}
+
+func TestMatchRanksExactAboveFuzzy(t *testing.T) {
+	tokens := Tokenize("contest")
+	exact := Match("AtCoder Beginner Contest", tokens)
+	fuzzy := Match("AtCoder Beginner Contst", tokens)
+	if exact.Score <= fuzzy.Score || fuzzy.Score == 0 {
+		t.Fatalf("exact score %v should be above fuzzy score %v > 0", exact.Score, fuzzy.Score)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		ranges []Range
+		want   string
+	}{
+		{"no ranges", "ABC 380", nil, "ABC 380"},
+		{"single", "ABC 380", []Range{{4, 7}}, "ABC <em>380</em>"},
+		{"multiple", "ABC 380", []Range{{0, 1}, {4, 7}}, "<em>A</em>BC <em>380</em>"},
+		{"escape", "<A&B>", []Range{{1, 2}}, "&lt;<em>A</em>&amp;B&gt;"},
+		{"han", "牛客周赛", []Range{{2, 4}}, "牛客<em>周赛</em>"},
+		// 越界或重叠的区间被忽略
+		{"out of range", "ABC", []Range{{1, 5}}, "ABC"},
+		{"overlapping", "ABCDEF", []Range{{0, 3}, {2, 4}}, "<em>ABC</em>DEF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Highlight(tt.text, tt.ranges); got != tt.want {
+				t.Fatalf("Highlight(%q, %v) = %q, want %q", tt.text, tt.ranges, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithinDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		max  int
+		want bool
+	}{
+		{"contest", "contest", 0, true},
+		{"contest", "contset", 1, false},
+		{"contest", "contset", 2, true},
+		{"contest", "contests", 1, true},
+		{"contest", "conest", 1, true},
+		{"contest", "cont", 1, false},
+		{"beginner", "begniner", 2, true},
+		{"round", "rounds", 0, false},
+		{"周赛", "周末", 1, true},
+		{"", "ab", 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if got := withinDistance(tt.a, tt.b, tt.max); got != tt.want {
+				t.Fatalf("withinDistance(%q, %q, %d) = %v, want %v", tt.a, tt.b, tt.max, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"AtCoder Beginner Contest 380", "atcoder beginner contest 380 abc"},
+		{"牛客周赛 Round 5", "牛客周赛 round 5 niukezhousai nkzs"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Text(tt.name); got != tt.want {
+				t.Fatalf("Text(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package timezone
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	// 内置时区数据，运行镜像中没有 zoneinfo 时也能加载 IANA 时区
+	_ "time/tzdata"
+
+	"github.com/gin-gonic/gin"
+	"nicccce-acm-calendar-api/config"
+	"nicccce-acm-calendar-api/tools"
+)
+
+// Header 客户端可通过该请求头设置默认时区，请求中的 tz 参数优先
+const Header = "X-Timezone"
+
+var (
+	defaultLocation = time.Local
+
+	// preference 获取请求用户设置的默认时区，由用户模块通过 SetPreference 注册
+	preference func(c *gin.Context) string
+
+	// offsetPattern 匹配 "+08:00"、"+0800"、"+8"、"UTC+8"、"GMT-05:30" 等形式的 UTC 偏移
+	offsetPattern = regexp.MustCompile(`^(?i:UTC|GMT)?([+-])(\d{1,2})(?::?(\d{2}))?$`)
+	// spacedOffsetPattern 匹配 URL 中未编码的 "+" 被解码为空格后的时间偏移，如 "2024-11-20T18:00:00 08:00"
+	spacedOffsetPattern = regexp.MustCompile(` (\d{2}:?\d{2})$`)
+)
+
+// Init 加载配置的默认时区
+func Init() {
+	name := config.Get().Timezone
+	if name == "" {
+		return
+	}
+	loc, err := Load(name)
+	tools.PanicOnErr(err)
+	defaultLocation = loc
+}
+
+// Default 默认时区
+func Default() *time.Location {
+	return defaultLocation
+}
+
+// Load 加载时区，支持 IANA 时区名和 UTC 偏移
+func Load(name string) (*time.Location, error) {
+	// URL 中未编码的 "+" 会被解码为空格
+	if strings.HasPrefix(name, " ") {
+		name = "+" + strings.TrimLeft(name, " ")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("empty timezone")
+	}
+
+	if m := offsetPattern.FindStringSubmatch(name); m != nil {
+		hours, _ := strconv.Atoi(m[2])
+		minutes := 0
+		if m[3] != "" {
+			minutes, _ = strconv.Atoi(m[3])
+		}
+		if hours > 14 || minutes >= 60 {
+			return nil, fmt.Errorf("invalid utc offset: %s", name)
+		}
+		seconds := hours*3600 + minutes*60
+		if m[1] == "-" {
+			seconds = -seconds
+		}
+		return time.FixedZone(fmt.Sprintf("UTC%s%02d:%02d", m[1], hours, minutes), seconds), nil
+	}
+
+	return time.LoadLocation(name)
+}
+
+// SetPreference 设置获取请求用户默认时区的函数，返回空字符串表示用户未设置
+func SetPreference(f func(c *gin.Context) string) {
+	preference = f
+}
+
+// FromRequest 获取请求使用的时区：依次为 tz 参数、X-Timezone 请求头、用户设置的默认时区和服务端默认时区
+// 用户设置的时区在保存时已校验，无法加载时忽略
+func FromRequest(c *gin.Context) (*time.Location, error) {
+	name := c.Query("tz")
+	if name == "" {
+		name = c.GetHeader(Header)
+	}
+	if name == "" {
+		if preference != nil {
+			if loc, err := Load(preference(c)); err == nil {
+				return loc, nil
+			}
+		}
+		return Default(), nil
+	}
+
+	loc, err := Load(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone: %s", name)
+	}
+	return loc, nil
+}
+
+// ParseTime 解析时间参数，支持以下格式：
+//   - RFC3339，如 "2024-11-20T18:00:00+08:00"，按其中的偏移解析
+//   - 不带偏移的日期时间，如 "2024-11-20T18:00:00"、"2024-11-20 18:00"，按 loc 解析
+//   - 日期，如 "2024-11-20"，为 loc 中当天零点，dateOnly 为 true
+//   - Unix 时间戳，10 位及以下为秒，更长的为毫秒
+//
+// 返回的时间均转换到 loc
+func ParseTime(s string, loc *time.Location) (t time.Time, dateOnly bool, err error) {
+	s = strings.TrimSpace(s)
+
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if len(strings.TrimPrefix(s, "-")) > 10 {
+			return time.UnixMilli(n).In(loc), false, nil
+		}
+		return time.Unix(n, 0).In(loc), false, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, spacedOffsetPattern.ReplaceAllString(s, "+$1")); err == nil {
+		return t.In(loc), false, nil
+	}
+
+	for _, layout := range []string{
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05",
+		"2006-01-02T15:04",
+		"2006-01-02 15:04",
+	} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, false, nil
+		}
+	}
+
+	if t, err := time.ParseInLocation(time.DateOnly, s, loc); err == nil {
+		return t, true, nil
+	}
+
+	return time.Time{}, false, fmt.Errorf("invalid time %q: use RFC3339, YYYY-MM-DD or a unix timestamp", s)
+}
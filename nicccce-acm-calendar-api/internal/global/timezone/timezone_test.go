@@ -0,0 +1,144 @@
+package timezone
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   string
+		offset int
+	}{
+		{"Asia/Shanghai", "Asia/Shanghai", 8 * 3600},
+		{"UTC", "UTC", 0},
+		{"+08:00", "UTC+08:00", 8 * 3600},
+		{"+0530", "UTC+05:30", 5*3600 + 30*60},
+		{"UTC-5", "UTC-05:00", -5 * 3600},
+		{"gmt+14", "UTC+14:00", 14 * 3600},
+		// URL 中未编码的 "+" 被解码为空格
+		{" 08:00", "UTC+08:00", 8 * 3600},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := Load(tt.name)
+			if err != nil {
+				t.Fatalf("Load(%q): %v", tt.name, err)
+			}
+			_, offset := time.Date(2024, 11, 20, 0, 0, 0, 0, loc).Zone()
+			if loc.String() != tt.want || offset != tt.offset {
+				t.Fatalf("Load(%q) = %s (%d), want %s (%d)", tt.name, loc, offset, tt.want, tt.offset)
+			}
+		})
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	for _, name := range []string{"", "  ", "+15", "+08:60", "Mars/Olympus", "08:00"} {
+		t.Run(name, func(t *testing.T) {
+			if loc, err := Load(name); err == nil {
+				t.Fatalf("Load(%q) = %s, want error", name, loc)
+			}
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	shanghai, err := Load("Asia/Shanghai")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2024, 11, 20, 18, 0, 0, 0, shanghai)
+
+	tests := []struct {
+		in       string
+		want     time.Time
+		dateOnly bool
+	}{
+		{"2024-11-20T18:00:00+08:00", want, false},
+		{"2024-11-20T10:00:00Z", want, false},
+		{"2024-11-20T18:00:00.000+08:00", want, false},
+		// URL 中未编码的 "+" 被解码为空格
+		{"2024-11-20T18:00:00 08:00", want, false},
+		{"2024-11-20T18:00:00", want, false},
+		{"2024-11-20 18:00:00", want, false},
+		{"2024-11-20T18:00", want, false},
+		{" 2024-11-20 18:00 ", want, false},
+		{"2024-11-20", time.Date(2024, 11, 20, 0, 0, 0, 0, shanghai), true},
+		{"1732096800", want, false},
+		{"1732096800000", want, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, dateOnly, err := ParseTime(tt.in, shanghai)
+			if err != nil {
+				t.Fatalf("ParseTime(%q): %v", tt.in, err)
+			}
+			if !got.Equal(tt.want) || dateOnly != tt.dateOnly {
+				t.Fatalf("ParseTime(%q) = %s, %v, want %s, %v", tt.in, got, dateOnly, tt.want, tt.dateOnly)
+			}
+			if got.Location() != shanghai {
+				t.Fatalf("ParseTime(%q) location = %s, want %s", tt.in, got.Location(), shanghai)
+			}
+		})
+	}
+}
+
+func TestParseTimeInvalid(t *testing.T) {
+	for _, in := range []string{"", "tomorrow", "2024-13-01", "2024/11/20", "18:00"} {
+		t.Run(in, func(t *testing.T) {
+			if got, _, err := ParseTime(in, time.UTC); err == nil {
+				t.Fatalf("ParseTime(%q) = %s, want error", in, got)
+			}
+		})
+	}
+}
+
+func TestFromRequest(t *testing.T) {
+	defer SetPreference(nil)
+
+	tests := []struct {
+		name       string
+		target     string
+		header     string
+		preference string
+		want       string
+		wantErr    bool
+	}{
+		{"default", "/", "", "", Default().String(), false},
+		{"query", "/?tz=Asia/Tokyo", "America/New_York", "Europe/London", "Asia/Tokyo", false},
+		{"header", "/", "America/New_York", "Europe/London", "America/New_York", false},
+		{"preference", "/", "", "Europe/London", "Europe/London", false},
+		// 用户设置无法加载时忽略，请求参数不合法时报错
+		{"invalid preference", "/", "", "Mars/Olympus", Default().String(), false},
+		{"invalid query", "/?tz=Mars/Olympus", "", "Europe/London", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetPreference(func(*gin.Context) string { return tt.preference })
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", tt.target, nil)
+			if tt.header != "" {
+				c.Request.Header.Set(Header, tt.header)
+			}
+
+			loc, err := FromRequest(c)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("FromRequest = %s, want error", loc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if loc.String() != tt.want {
+				t.Fatalf("FromRequest = %s, want %s", loc, tt.want)
+			}
+		})
+	}
+}
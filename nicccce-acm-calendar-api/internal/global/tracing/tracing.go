@@ -0,0 +1,105 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"nicccce-acm-calendar-api/config"
+	"nicccce-acm-calendar-api/tools"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterNone   = ""
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+
+	defaultServiceName = "nicccce-acm-calendar-api"
+)
+
+var provider *sdktrace.TracerProvider
+
+// Init 根据配置初始化全局 TracerProvider
+// 未配置导出器时不创建 provider，otel 默认的 noop 实现不会产生任何开销
+func Init() {
+	// 无论是否导出，都解析上游传入的 traceparent，保证日志中的 trace_id 与调用方一致
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	cfg := config.Get().Trace
+	if cfg.Exporter == ExporterNone {
+		return
+	}
+
+	exporter, err := newExporter(cfg)
+	tools.PanicOnErr(err)
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(ServiceName()),
+		semconv.DeploymentEnvironment(string(config.Get().Mode)),
+	))
+	tools.PanicOnErr(err)
+
+	sampler := sdktrace.ParentBased(sdktrace.AlwaysSample())
+	if cfg.SampleRatio > 0 && cfg.SampleRatio < 1 {
+		sampler = sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))
+	}
+
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sampler),
+	)
+	otel.SetTracerProvider(provider)
+}
+
+// Shutdown 导出剩余的 span 并关闭 provider
+func Shutdown(ctx context.Context) error {
+	if provider == nil {
+		return nil
+	}
+	return provider.Shutdown(ctx)
+}
+
+// Tracer 获取指定模块的 Tracer
+func Tracer(name string) trace.Tracer {
+	return otel.Tracer(name)
+}
+
+// ServiceName 上报的服务名
+func ServiceName() string {
+	if name := config.Get().Trace.ServiceName; name != "" {
+		return name
+	}
+	return defaultServiceName
+}
+
+func newExporter(cfg config.Trace) (sdktrace.SpanExporter, error) {
+	switch strings.ToLower(cfg.Exporter) {
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(context.Background(), opts...)
+	default:
+		return nil, fmt.Errorf("unsupported trace exporter: %s", cfg.Exporter)
+	}
+}
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKey 第三方调用方使用的 API Key，数据库只保存 Key 的摘要，原文仅在创建时返回一次
+type APIKey struct {
+	Model
+	Name       string     `gorm:"size:100;not null;comment:名称"`
+	Prefix     string     `gorm:"size:16;not null;comment:Key前缀，用于辨认"`
+	KeyHash    string     `gorm:"size:64;not null;uniqueIndex;comment:Key的SHA-256摘要"`
+	Scopes     string     `gorm:"size:255;not null;default:'';comment:授权范围，逗号分隔"`
+	RateLimit  int        `gorm:"default:0;comment:每分钟请求数上限，0表示使用默认值"`
+	DailyQuota int        `gorm:"default:0;comment:24小时内请求数上限，0表示使用默认值"`
+	ExpiresAt  *time.Time `gorm:"comment:过期时间"`
+	RevokedAt  *time.Time `gorm:"index;comment:吊销时间"`
+	LastUsedAt *time.Time `gorm:"comment:最后使用时间"`
+}
+
+// ScopeList 授权范围列表
+func (k *APIKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(k.Scopes, ",")
+}
+
+// HasScope 是否具有授权范围
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.ScopeList() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ActiveAt API Key 在 t 时刻是否可用，已吊销或已过期时不可用
+func (k *APIKey) ActiveAt(t time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || t.Before(*k.ExpiresAt)
+}
+
+// APIKeyUsage API Key 每日的请求统计，日期按默认时区划分
+type APIKeyUsage struct {
+	ID       uint   `gorm:"primarykey"`
+	APIKeyID uint   `gorm:"not null;uniqueIndex:idx_api_key_usages_key_date,priority:1;comment:API Key ID"`
+	Date     string `gorm:"size:10;not null;uniqueIndex:idx_api_key_usages_key_date,priority:2;comment:日期(YYYY-MM-DD)"`
+	Requests int64  `gorm:"not null;default:0;comment:请求数"`
+	Rejected int64  `gorm:"not null;default:0;comment:因超出配额被拒绝的请求数"`
+}
+
+// APIKeyDto 用于API返回，不包含 Key 原文和摘要
+type APIKeyDto struct {
+	Dto
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	RateLimit  int        `json:"rate_limit"`
+	DailyQuota int        `json:"daily_quota"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+// APIKeyCreatedDto 创建 API Key 的返回结果，Key 原文只在此时返回
+type APIKeyCreatedDto struct {
+	APIKeyDto
+	Key string `json:"key"`
+}
+
+// APIKeyUsageDto API Key 某日的请求统计
+type APIKeyUsageDto struct {
+	Date     string `json:"date"`
+	Requests int64  `json:"requests"`
+	Rejected int64  `json:"rejected"`
+}
+
+func (k *APIKey) ToDto() APIKeyDto {
+	return APIKeyDto{
+		Dto: Dto{
+			ID:         k.ID,
+			CreateTime: k.CreateTime(),
+			UpdateTime: k.UpdateTime(),
+		},
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.ScopeList(),
+		RateLimit:  k.RateLimit,
+		DailyQuota: k.DailyQuota,
+		ExpiresAt:  k.ExpiresAt,
+		RevokedAt:  k.RevokedAt,
+		LastUsedAt: k.LastUsedAt,
+	}
+}
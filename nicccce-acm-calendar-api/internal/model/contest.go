@@ -4,49 +4,113 @@ import (
 	"time"
 )
 
+const (
+	ContestStatusUpcoming = "upcoming"
+	ContestStatusRunning  = "running"
+	ContestStatusFinished = "finished"
+)
+
 type Contest struct {
 	Model
 	Name            string    `gorm:"size:255;not null;comment:比赛名称"`
-	Platform        string    `gorm:"size:50;not null;index;comment:比赛平台"`
+	Platform        string    `gorm:"size:50;not null;index;uniqueIndex:idx_contests_platform_source_id,priority:1;comment:比赛平台"`
 	StartTime       time.Time `gorm:"not null;index;comment:开始时间"`
 	EndTime         time.Time `gorm:"not null;index;comment:结束时间"`
 	DurationSeconds int64     `gorm:"not null;comment:持续时间(秒)"`
 	ContestURL      string    `gorm:"size:500;not null;comment:比赛链接"`
 	Status          string    `gorm:"size:20;default:'upcoming';index;comment:比赛状态(upcoming/running/finished)"`
-	SourceID        string    `gorm:"size:100;index;comment:原始平台ID"`
+	SourceID        string    `gorm:"size:100;uniqueIndex:idx_contests_platform_source_id,priority:2;comment:原始平台ID"`
 	LastUpdated     time.Time `gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP;comment:最后更新时间"`
+	// SearchText 由名称生成的搜索文本，用于搜索时预筛选，见 search.Text
+	SearchText string `gorm:"size:1000;comment:搜索文本(分词、首字母缩写和拼音)"`
 }
 
+// ContestPlatform 平台信息，启动时由平台注册表写入，见 platform.All
 type ContestPlatform struct {
 	Model
-	Name           string `gorm:"size:50;not null;uniqueIndex;comment:平台名称"`
+	Name           string `gorm:"size:50;not null;uniqueIndex;comment:平台标识"`
 	DisplayName    string `gorm:"size:50;not null;comment:显示名称"`
+	DisplayNameEn  string `gorm:"size:50;comment:英文显示名称"`
+	Homepage       string `gorm:"size:255;comment:平台主页"`
+	Icon           string `gorm:"size:500;comment:图标地址"`
+	Color          string `gorm:"size:20;comment:品牌色"`
+	SortOrder      int    `gorm:"default:0;comment:展示顺序"`
 	APIURL         string `gorm:"size:500;comment:API地址"`
 	IsActive       bool   `gorm:"default:true;comment:是否激活"`
 	UpdateInterval int    `gorm:"default:3600;comment:更新间隔(秒)"`
 }
 
+// PlatformDto 平台信息，显示名称按请求语言本地化
+type PlatformDto struct {
+	Slug     string            `json:"slug"`
+	Name     string            `json:"name"`
+	Names    map[string]string `json:"names"`
+	Homepage string            `json:"homepage"`
+	Icon     string            `json:"icon"`
+	Color    string            `json:"color"`
+	IsActive bool              `json:"is_active"`
+}
+
 type ContestRefreshLog struct {
 	Model
 	Platform     string `gorm:"size:50;not null;index;comment:平台名称"`
 	Status       string `gorm:"size:20;not null;comment:刷新状态(success/failed)"`
 	Message      string `gorm:"size:500;comment:刷新消息"`
+	FetchedCount int    `gorm:"default:0;comment:获取比赛数量"`
 	NewCount     int    `gorm:"default:0;comment:新增比赛数量"`
 	UpdatedCount int    `gorm:"default:0;comment:更新比赛数量"`
 	Duration     int64  `gorm:"comment:耗时(毫秒)"`
+	Attempts     int    `gorm:"default:0;comment:尝试次数"`
+	BreakerState string `gorm:"size:20;comment:熔断器状态(closed/open/half_open)"`
+	RequestID    string `gorm:"size:64;index;comment:触发刷新的请求ID"`
+}
+
+// StatusAt 根据开始和结束时间计算比赛在 t 时刻的状态
+// 数据库中的 status 列由定时器在比赛开始和结束时更新，可能有短暂延迟，返回给客户端的状态以此为准
+func (c *Contest) StatusAt(t time.Time) string {
+	switch {
+	case t.Before(c.StartTime):
+		return ContestStatusUpcoming
+	case t.After(c.EndTime):
+		return ContestStatusFinished
+	default:
+		return ContestStatusRunning
+	}
 }
 
 // ContestDto 用于API返回
 type ContestDto struct {
 	Dto
-	Name            string    `json:"name"`
-	Platform        string    `json:"platform"`
-	StartTime       time.Time `json:"start_time"`
-	EndTime         time.Time `json:"end_time"`
-	DurationSeconds int64     `json:"duration_seconds"`
-	ContestURL      string    `json:"contest_url"`
-	Status          string    `json:"status"`
-	TimeRemaining   string    `json:"time_remaining,omitempty"`
+	Name            string     `json:"name"`
+	Platform        string     `json:"platform"`
+	PlatformName    string     `json:"platform_name,omitempty"`
+	StartTime       time.Time  `json:"start_time"`
+	EndTime         time.Time  `json:"end_time"`
+	DurationSeconds int64      `json:"duration_seconds"`
+	ContestURL      string     `json:"contest_url"`
+	Status          string     `json:"status"`
+	TimeRemaining   string     `json:"time_remaining,omitempty"`
+	Countdown       *Countdown `json:"countdown,omitempty"`
+}
+
+// Countdown 响应时距比赛开始和结束的秒数，已开始或已结束时对应字段为 0
+type Countdown struct {
+	SecondsToStart int64 `json:"seconds_to_start"`
+	SecondsToEnd   int64 `json:"seconds_to_end"`
+}
+
+// CountdownAt 计算 t 时刻距比赛开始和结束的秒数
+func (c *Contest) CountdownAt(t time.Time) *Countdown {
+	return &Countdown{
+		SecondsToStart: max(int64(c.StartTime.Sub(t)/time.Second), 0),
+		SecondsToEnd:   max(int64(c.EndTime.Sub(t)/time.Second), 0),
+	}
+}
+
+// In 将比赛时间转换到 loc 时区，序列化时带有对应的偏移
+func (d *ContestDto) In(loc *time.Location) {
+	d.StartTime = d.StartTime.In(loc)
+	d.EndTime = d.EndTime.In(loc)
 }
 
 func (c *Contest) ToDto() ContestDto {
@@ -62,6 +126,6 @@ func (c *Contest) ToDto() ContestDto {
 		EndTime:         c.EndTime,
 		DurationSeconds: c.DurationSeconds,
 		ContestURL:      c.ContestURL,
-		Status:          c.Status,
+		Status:          c.StatusAt(time.Now()),
 	}
 }
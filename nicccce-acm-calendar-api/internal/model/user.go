@@ -0,0 +1,19 @@
+package model
+
+// UserPreference 用户的偏好设置，用户由 Token 中的学号标识
+type UserPreference struct {
+	Model
+	StudentID string `gorm:"size:64;not null;uniqueIndex;comment:学号"`
+	Timezone  string `gorm:"size:64;not null;default:'';comment:默认时区，为空表示使用服务端默认时区"`
+}
+
+// UserPreferenceDto 用于API返回
+type UserPreferenceDto struct {
+	Timezone string `json:"timezone"`
+}
+
+func (p *UserPreference) ToDto() UserPreferenceDto {
+	return UserPreferenceDto{
+		Timezone: p.Timezone,
+	}
+}
@@ -0,0 +1,212 @@
+package apikey
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"nicccce-acm-calendar-api/internal/global/apikey"
+	"nicccce-acm-calendar-api/internal/global/database"
+	"nicccce-acm-calendar-api/internal/global/response"
+	"nicccce-acm-calendar-api/internal/global/timezone"
+	"nicccce-acm-calendar-api/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultUsageDays = 30
+	maxUsageDays     = 366
+)
+
+type createAPIKeyRequest struct {
+	Name       string     `json:"name" binding:"required,max=100"`
+	Scopes     []string   `json:"scopes"`
+	RateLimit  int        `json:"rate_limit" binding:"min=0"`
+	DailyQuota int        `json:"daily_quota" binding:"min=0"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
+
+// updateAPIKeyRequest 只修改传入的字段
+type updateAPIKeyRequest struct {
+	Name       *string    `json:"name" binding:"omitempty,min=1,max=100"`
+	Scopes     []string   `json:"scopes"`
+	RateLimit  *int       `json:"rate_limit" binding:"omitempty,min=0"`
+	DailyQuota *int       `json:"daily_quota" binding:"omitempty,min=0"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
+
+// APIKeyUsage API Key 在一段时间内的请求统计
+type APIKeyUsage struct {
+	APIKeyID      uint                   `json:"api_key_id"`
+	From          string                 `json:"from"`
+	To            string                 `json:"to"`
+	TotalRequests int64                  `json:"total_requests"`
+	TotalRejected int64                  `json:"total_rejected"`
+	Days          []model.APIKeyUsageDto `json:"days"`
+}
+
+// ListAPIKeys 获取所有 API Key，包括已吊销的
+func (m *ModuleAPIKey) ListAPIKeys(c *gin.Context) {
+	var keys []model.APIKey
+	if err := database.DB.Order("id DESC").Find(&keys).Error; err != nil {
+		response.Fail(c, response.ErrDatabase.WithOrigin(err))
+		return
+	}
+
+	dtos := make([]model.APIKeyDto, 0, len(keys))
+	for _, key := range keys {
+		dtos = append(dtos, key.ToDto())
+	}
+	response.Success(c, dtos)
+}
+
+// CreateAPIKey 创建 API Key，Key 原文只在响应中返回一次
+func (m *ModuleAPIKey) CreateAPIKey(c *gin.Context) {
+	var req createAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, response.ErrInvalidRequest.WithTips(err.Error()))
+		return
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		response.Fail(c, response.ErrInvalidRequest.WithTips("expires_at must be in the future"))
+		return
+	}
+	if _, err := apikey.JoinScopes(req.Scopes); err != nil {
+		response.Fail(c, response.ErrInvalidRequest.WithTips(err.Error()))
+		return
+	}
+
+	key := model.APIKey{
+		Name:       req.Name,
+		RateLimit:  req.RateLimit,
+		DailyQuota: req.DailyQuota,
+		ExpiresAt:  req.ExpiresAt,
+	}
+	raw, err := apikey.Create(c.Request.Context(), &key, req.Scopes)
+	if err != nil {
+		response.Fail(c, response.ErrDatabase.WithOrigin(err))
+		return
+	}
+	log.InfoContext(c.Request.Context(), "API key created", "id", key.ID, "name", key.Name, "scopes", key.Scopes)
+
+	response.Success(c, model.APIKeyCreatedDto{APIKeyDto: key.ToDto(), Key: raw})
+}
+
+// GetAPIKey 获取 API Key 详情
+func (m *ModuleAPIKey) GetAPIKey(c *gin.Context) {
+	key, ok := findAPIKey(c)
+	if !ok {
+		return
+	}
+	response.Success(c, key.ToDto())
+}
+
+// UpdateAPIKey 修改 API Key 的名称、授权范围、配额或过期时间
+func (m *ModuleAPIKey) UpdateAPIKey(c *gin.Context) {
+	var req updateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, response.ErrInvalidRequest.WithTips(err.Error()))
+		return
+	}
+	key, ok := findAPIKey(c)
+	if !ok {
+		return
+	}
+
+	if req.Name != nil {
+		key.Name = *req.Name
+	}
+	if req.Scopes != nil {
+		scopes, err := apikey.JoinScopes(req.Scopes)
+		if err != nil {
+			response.Fail(c, response.ErrInvalidRequest.WithTips(err.Error()))
+			return
+		}
+		key.Scopes = scopes
+	}
+	if req.RateLimit != nil {
+		key.RateLimit = *req.RateLimit
+	}
+	if req.DailyQuota != nil {
+		key.DailyQuota = *req.DailyQuota
+	}
+	if req.ExpiresAt != nil {
+		key.ExpiresAt = req.ExpiresAt
+	}
+
+	if err := apikey.Save(c.Request.Context(), key); err != nil {
+		response.Fail(c, response.ErrDatabase.WithOrigin(err))
+		return
+	}
+	response.Success(c, key.ToDto())
+}
+
+// RevokeAPIKey 吊销 API Key，吊销后不可恢复；记录保留用于查看请求统计
+func (m *ModuleAPIKey) RevokeAPIKey(c *gin.Context) {
+	key, ok := findAPIKey(c)
+	if !ok {
+		return
+	}
+	if err := apikey.Revoke(c.Request.Context(), key); err != nil {
+		response.Fail(c, response.ErrDatabase.WithOrigin(err))
+		return
+	}
+	log.InfoContext(c.Request.Context(), "API key revoked", "id", key.ID, "name", key.Name)
+
+	response.Success(c, key.ToDto())
+}
+
+// GetAPIKeyUsage 获取 API Key 最近若干天的每日请求统计，统计约有 30 秒延迟
+func (m *ModuleAPIKey) GetAPIKeyUsage(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(defaultUsageDays)))
+	if err != nil || days < 1 || days > maxUsageDays {
+		response.Fail(c, response.ErrInvalidRequest.WithTips("days must be between 1 and "+strconv.Itoa(maxUsageDays)))
+		return
+	}
+	key, ok := findAPIKey(c)
+	if !ok {
+		return
+	}
+
+	today := time.Now().In(timezone.Default())
+	from := today.AddDate(0, 0, -(days - 1)).Format(time.DateOnly)
+	to := today.Format(time.DateOnly)
+
+	var rows []model.APIKeyUsage
+	if err := database.DB.Where("api_key_id = ? AND date >= ? AND date <= ?", key.ID, from, to).
+		Order("date ASC").Find(&rows).Error; err != nil {
+		response.Fail(c, response.ErrDatabase.WithOrigin(err))
+		return
+	}
+
+	usage := APIKeyUsage{APIKeyID: key.ID, From: from, To: to, Days: make([]model.APIKeyUsageDto, 0, len(rows))}
+	for _, row := range rows {
+		usage.TotalRequests += row.Requests
+		usage.TotalRejected += row.Rejected
+		usage.Days = append(usage.Days, model.APIKeyUsageDto{Date: row.Date, Requests: row.Requests, Rejected: row.Rejected})
+	}
+	response.Success(c, usage)
+}
+
+// findAPIKey 按路径参数 id 查询 API Key，失败时已响应错误
+func findAPIKey(c *gin.Context) (*model.APIKey, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Fail(c, response.ErrInvalidRequest)
+		return nil, false
+	}
+
+	var key model.APIKey
+	err = database.DB.First(&key, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		response.Fail(c, response.ErrNotFound)
+		return nil, false
+	}
+	if err != nil {
+		response.Fail(c, response.ErrDatabase.WithOrigin(err))
+		return nil, false
+	}
+	return &key, true
+}
@@ -0,0 +1,59 @@
+package apikey
+
+import (
+	"net/http"
+
+	"nicccce-acm-calendar-api/internal/global/apikey"
+	"nicccce-acm-calendar-api/internal/global/openapi"
+	"nicccce-acm-calendar-api/internal/model"
+)
+
+const tagAPIKeys = "api-keys"
+
+// Docs API Key 管理接口的文档
+func (m *ModuleAPIKey) Docs() []openapi.Route {
+	id := openapi.Param{Name: "id", In: "path", Type: "integer", Description: "API Key ID"}
+	notFound := []int{http.StatusNotFound}
+	return []openapi.Route{
+		{
+			Method: http.MethodGet, Path: "/admin/api-keys", Tag: tagAPIKeys, Scope: apikey.ScopeAdmin, Auth: true,
+			Summary: "获取所有 API Key",
+			Data:    []model.APIKeyDto{},
+		},
+		{
+			Method: http.MethodPost, Path: "/admin/api-keys", Tag: tagAPIKeys, Scope: apikey.ScopeAdmin, Auth: true,
+			Summary:     "创建 API Key",
+			Description: "Key 原文只在响应中返回一次；未指定授权范围时只授予 contests:read，配额为 0 时使用默认值",
+			Body:        createAPIKeyRequest{},
+			Data:        model.APIKeyCreatedDto{},
+		},
+		{
+			Method: http.MethodGet, Path: "/admin/api-keys/:id", Tag: tagAPIKeys, Scope: apikey.ScopeAdmin, Auth: true,
+			Summary: "获取 API Key 详情",
+			Params:  []openapi.Param{id},
+			Data:    model.APIKeyDto{}, Errors: notFound,
+		},
+		{
+			Method: http.MethodPatch, Path: "/admin/api-keys/:id", Tag: tagAPIKeys, Scope: apikey.ScopeAdmin, Auth: true,
+			Summary:     "修改 API Key",
+			Description: "只修改传入的字段",
+			Params:      []openapi.Param{id},
+			Body:        updateAPIKeyRequest{},
+			Data:        model.APIKeyDto{}, Errors: notFound,
+		},
+		{
+			Method: http.MethodDelete, Path: "/admin/api-keys/:id", Tag: tagAPIKeys, Scope: apikey.ScopeAdmin, Auth: true,
+			Summary:     "吊销 API Key",
+			Description: "吊销后不可恢复，记录保留用于查看请求统计",
+			Params:      []openapi.Param{id},
+			Data:        model.APIKeyDto{}, Errors: notFound,
+		},
+		{
+			Method: http.MethodGet, Path: "/admin/api-keys/:id/usage", Tag: tagAPIKeys, Scope: apikey.ScopeAdmin, Auth: true,
+			Summary:     "获取 API Key 的请求统计",
+			Description: "按默认时区统计每日请求数，约有 30 秒延迟",
+			Params:      []openapi.Param{id, {Name: "days", Type: "integer", Description: "统计最近的天数，默认 30，最大 366"}},
+			Data:        APIKeyUsage{}, Errors: notFound,
+		},
+	}
+}
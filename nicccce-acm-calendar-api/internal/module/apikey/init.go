@@ -0,0 +1,35 @@
+package apikey
+
+import (
+	"context"
+	"log/slog"
+
+	"nicccce-acm-calendar-api/internal/global/apikey"
+	"nicccce-acm-calendar-api/internal/global/logger"
+)
+
+var log *slog.Logger
+
+// adminRoleID 用户 Token 的角色 ID 不小于该值时可管理 API Key
+const adminRoleID = 1
+
+type ModuleAPIKey struct{}
+
+func (m *ModuleAPIKey) GetName() string {
+	return "APIKey"
+}
+
+func (m *ModuleAPIKey) Init() {
+	log = logger.New("APIKey")
+}
+
+// Start 启动请求统计的定期写入
+func (m *ModuleAPIKey) Start() {
+	apikey.Start()
+}
+
+// Stop 写入剩余的请求统计
+func (m *ModuleAPIKey) Stop(ctx context.Context) {
+	apikey.Stop(ctx)
+	log.Info("APIKey stopped")
+}
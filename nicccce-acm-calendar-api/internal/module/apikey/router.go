@@ -0,0 +1,22 @@
+package apikey
+
+import (
+	"nicccce-acm-calendar-api/internal/global/apikey"
+	"nicccce-acm-calendar-api/internal/global/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (m *ModuleAPIKey) InitRouter(r *gin.RouterGroup) {
+	// API Key 管理，需要管理员 Token 或具有 admin 授权范围的 API Key
+	keyGroup := r.Group("/admin/api-keys")
+	keyGroup.Use(middleware.AuthOrAPIKey(adminRoleID, apikey.ScopeAdmin))
+	{
+		keyGroup.GET("", m.ListAPIKeys)
+		keyGroup.POST("", m.CreateAPIKey)
+		keyGroup.GET("/:id", m.GetAPIKey)
+		keyGroup.PATCH("/:id", m.UpdateAPIKey)
+		keyGroup.DELETE("/:id", m.RevokeAPIKey)
+		keyGroup.GET("/:id/usage", m.GetAPIKeyUsage)
+	}
+}
@@ -3,6 +3,8 @@ package crawler
 import (
 	"context"
 	"fmt"
+	"nicccce-acm-calendar-api/internal/global/httpclient"
+	"nicccce-acm-calendar-api/internal/global/platform"
 	"nicccce-acm-calendar-api/internal/model"
 	"regexp"
 	"strconv"
@@ -11,6 +13,8 @@ import (
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/go-resty/resty/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type AtCoderCrawler struct {
@@ -18,12 +22,12 @@ type AtCoderCrawler struct {
 }
 
 func (c *AtCoderCrawler) Name() string {
-	return "AtCoder"
+	return platform.AtCoder
 }
 
 func (c *AtCoderCrawler) Crawl(ctx context.Context) ([]*model.Contest, error) {
 	if c.client == nil {
-		c.client = resty.New()
+		c.client = httpclient.New()
 	}
 
 	url := "https://atcoder.jp/contests/"
@@ -40,6 +44,9 @@ func (c *AtCoderCrawler) Crawl(ctx context.Context) ([]*model.Contest, error) {
 		return nil, fmt.Errorf("AtCoder returned status code: %d", resp.StatusCode())
 	}
 
+	_, span := tracer.Start(ctx, "crawler.parse", trace.WithAttributes(attribute.String("platform", c.Name())))
+	defer span.End()
+
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(resp.String()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
@@ -52,9 +59,10 @@ func (c *AtCoderCrawler) Crawl(ctx context.Context) ([]*model.Contest, error) {
 	doc.Find("#contest-table-upcoming .table-default tbody tr").Each(func(i int, s *goquery.Selection) {
 		contest, err := c.parseContestRow(s, now)
 		if err != nil {
-			fmt.Printf("Failed to parse AtCoder contest row: %v\n", err)
+			recordParseError(ctx, c.Name(), fmt.Errorf("failed to parse AtCoder contest row: %w", err))
 			return
 		}
+		recordParsed(ctx)
 		contests = append(contests, contest)
 	})
 
@@ -62,15 +70,17 @@ func (c *AtCoderCrawler) Crawl(ctx context.Context) ([]*model.Contest, error) {
 	doc.Find("#contest-table-active .table-default tbody tr").Each(func(i int, s *goquery.Selection) {
 		contest, err := c.parseContestRow(s, now)
 		if err != nil {
-			fmt.Printf("Failed to parse AtCoder contest row: %v\n", err)
+			recordParseError(ctx, c.Name(), fmt.Errorf("failed to parse AtCoder contest row: %w", err))
 			return
 		}
+		recordParsed(ctx)
 		if contest != nil {
 			contest.Status = "running"
 			contests = append(contests, contest)
 		}
 	})
 
+	span.SetAttributes(attribute.Int("contests", len(contests)))
 	return contests, nil
 }
 
@@ -115,7 +125,7 @@ func (c *AtCoderCrawler) parseContestRow(s *goquery.Selection, now time.Time) (*
 
 	return &model.Contest{
 		Name:            contestName,
-		Platform:        "AtCoder",
+		Platform:        c.Name(),
 		StartTime:       startTime,
 		EndTime:         endTime,
 		DurationSeconds: duration,
@@ -0,0 +1,71 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"nicccce-acm-calendar-api/internal/global/cache"
+	"nicccce-acm-calendar-api/internal/global/i18n"
+	"nicccce-acm-calendar-api/internal/global/platform"
+	"nicccce-acm-calendar-api/internal/global/timezone"
+	"nicccce-acm-calendar-api/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 比赛查询结果的缓存标签：详情依赖比赛标签，列表依赖其中可能出现的平台的标签
+// 比赛新增、变化、状态变化或删除时使比赛标签和所属平台的标签失效
+
+func contestTag(id uint) string {
+	return fmt.Sprintf("contest:%d", id)
+}
+
+func platformTag(slug string) string {
+	return "platform:" + slug
+}
+
+// platformTags 列表可能包含的平台的标签，未指定平台时为所有平台
+func platformTags(slug string) []string {
+	if slug != "" {
+		return []string{platformTag(slug)}
+	}
+	tags := make([]string, 0, len(platform.All()))
+	for _, p := range platform.All() {
+		tags = append(tags, platformTag(p.Slug))
+	}
+	return tags
+}
+
+// cacheScope 列表查询的缓存键和依赖的标签，缓存键需包含除分页和排序外的全部过滤条件
+type cacheScope struct {
+	key  string
+	tags []string
+}
+
+// invalidateContests 比赛数据变化后使相关缓存失效
+func invalidateContests(ctx context.Context, contests []model.Contest) {
+	if len(contests) == 0 {
+		return
+	}
+	tags := make([]string, 0, len(contests)+1)
+	for _, contest := range contests {
+		tags = append(tags, contestTag(contest.ID), platformTag(contest.Platform))
+	}
+	cache.Invalidate(ctx, tags...)
+}
+
+// countdownBucket 剩余时间和倒计时的更新粒度，与 time_remaining 的精度一致
+const countdownBucket = time.Minute
+
+// notModified 按缓存内容生成 ETag，客户端缓存仍有效时响应 304 并返回 true
+// 响应内容按语言和时区生成，二者都参与 ETag 的计算；时区可能取自用户设置，因此也随 Authorization 变化
+// 剩余时间和倒计时随时间变化，ETag 还包含当前所在的分钟，客户端缓存最多保留到这一分钟结束
+func notModified(c *gin.Context, digest string, loc *time.Location, lang i18n.Lang) bool {
+	c.Writer.Header().Add("Vary", timezone.Header)
+	c.Writer.Header().Add("Vary", "Authorization")
+	bucket := time.Now().Truncate(countdownBucket)
+	etag := cache.ETag(digest, string(lang), loc.String(), strconv.FormatInt(bucket.Unix(), 10))
+	return cache.NotModified(c, etag, bucket.Add(countdownBucket))
+}
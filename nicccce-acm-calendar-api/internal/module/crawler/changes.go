@@ -0,0 +1,157 @@
+package crawler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"nicccce-acm-calendar-api/internal/global/database"
+	"nicccce-acm-calendar-api/internal/global/i18n"
+	"nicccce-acm-calendar-api/internal/global/response"
+	"nicccce-acm-calendar-api/internal/global/timezone"
+	"nicccce-acm-calendar-api/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultChangesPageSize = maxPageSize
+
+	// changesSettleDelay 只返回该时间之前的变更
+	// 比赛的更新时间在事务提交前就已确定，留出余量使游标不会越过尚未提交的事务，多实例间的时钟偏差同理
+	changesSettleDelay = 15 * time.Second
+
+	// changedAtColumn 比赛最后一次变化的时间，软删除不会修改 updated_at
+	changedAtColumn = "COALESCE(deleted_at, updated_at)"
+)
+
+// ContestChanges 增量同步结果
+type ContestChanges struct {
+	Created []model.ContestDto `json:"created"`
+	Updated []model.ContestDto `json:"updated"`
+	// Deleted 已删除比赛的ID
+	Deleted []uint `json:"deleted"`
+	// Cursor 下次同步时作为 since 传入
+	Cursor  string `json:"cursor"`
+	HasMore bool   `json:"has_more"`
+}
+
+// changesCursor 游标记录已同步到的变化时间和该时间下最后一条比赛的ID，按 (变化时间, id) 单调递增
+type changesCursor struct {
+	Time time.Time `json:"t"`
+	ID   uint      `json:"id"`
+	// Initial 首次同步尚未完成，后续页中未删除的比赛全部视为新增
+	Initial bool `json:"i,omitempty"`
+}
+
+// GetContestChanges 增量同步：返回游标之后新增、更新和删除的比赛以及新的游标
+// 不传 since 时为首次同步，返回所有未删除的比赛；has_more 为 true 时应立即用新游标继续同步
+func (m *ModuleCrawler) GetContestChanges(c *gin.Context) {
+	var since *changesCursor
+	if s := c.Query("since"); s != "" {
+		cursor, err := decodeChangesCursor(s)
+		if err != nil {
+			response.Fail(c, response.ErrInvalidRequest.WithTips("invalid since cursor"))
+			return
+		}
+		since = cursor
+	}
+
+	_, pageSize, err := parsePage(c, defaultChangesPageSize)
+	if err != nil {
+		response.Fail(c, response.ErrInvalidRequest.WithTips(err.Error()))
+		return
+	}
+	loc, err := timezone.FromRequest(c)
+	if err != nil {
+		response.Fail(c, response.ErrInvalidRequest.WithTips(err.Error()))
+		return
+	}
+
+	// 统一转换到本地时区，SQLite 按字符串比较时间
+	upper := time.Now().Add(-changesSettleDelay).In(time.Local)
+	query := database.DB.Model(&model.Contest{}).Where(changedAtColumn+" < ?", upper)
+	if since != nil {
+		t := since.Time.In(time.Local)
+		// 包括已软删除的比赛，首次同步翻页期间删除的比赛同样需要通知客户端
+		query = query.Unscoped().Where(
+			"("+changedAtColumn+" > ? OR ("+changedAtColumn+" = ? AND id > ?))",
+			t, t, since.ID,
+		)
+	}
+
+	// 多取一条用于判断是否还有下一页
+	var contests []model.Contest
+	if err := query.Order(changedAtColumn + " ASC").Order("id ASC").
+		Limit(pageSize + 1).Find(&contests).Error; err != nil {
+		response.Fail(c, response.ErrServerInternal)
+		return
+	}
+
+	changes := ContestChanges{
+		Created: []model.ContestDto{},
+		Updated: []model.ContestDto{},
+		Deleted: []uint{},
+	}
+	if len(contests) > pageSize {
+		contests = contests[:pageSize]
+		changes.HasMore = true
+	}
+
+	lang := i18n.FromRequest(c)
+	for _, contest := range contests {
+		switch {
+		case contest.DeletedAt.Valid:
+			changes.Deleted = append(changes.Deleted, contest.ID)
+		case since == nil || since.Initial || contest.CreatedAt.After(since.Time):
+			changes.Created = append(changes.Created, toContestDto(&contest, loc, lang))
+		default:
+			changes.Updated = append(changes.Updated, toContestDto(&contest, loc, lang))
+		}
+	}
+
+	// 没有更多变更时游标推进到 upper，此后变化的比赛时间都不早于 upper
+	next := changesCursor{Time: upper}
+	if changes.HasMore {
+		last := &contests[len(contests)-1]
+		next = changesCursor{Time: changedAt(last), ID: last.ID, Initial: since == nil || since.Initial}
+	} else if since != nil && since.Time.After(upper) {
+		next = *since
+	}
+	cursor, err := encodeChangesCursor(next)
+	if err != nil {
+		response.Fail(c, response.ErrServerInternal.WithOrigin(err))
+		return
+	}
+	changes.Cursor = cursor
+
+	response.Success(c, changes)
+}
+
+// changedAt 比赛最后一次变化的时间，与 changedAtColumn 一致
+func changedAt(contest *model.Contest) time.Time {
+	if contest.DeletedAt.Valid {
+		return contest.DeletedAt.Time
+	}
+	return contest.UpdatedAt
+}
+
+func encodeChangesCursor(cursor changesCursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeChangesCursor(s string) (*changesCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var cursor changesCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"nicccce-acm-calendar-api/internal/global/httpclient"
+	"nicccce-acm-calendar-api/internal/global/platform"
 	"nicccce-acm-calendar-api/internal/model"
 	"time"
 
 	"github.com/go-resty/resty/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type CodeforcesCrawler struct {
@@ -15,12 +19,12 @@ type CodeforcesCrawler struct {
 }
 
 func (c *CodeforcesCrawler) Name() string {
-	return "Codeforces"
+	return platform.Codeforces
 }
 
 func (c *CodeforcesCrawler) Crawl(ctx context.Context) ([]*model.Contest, error) {
 	if c.client == nil {
-		c.client = resty.New()
+		c.client = httpclient.New()
 	}
 
 	// Codeforces API endpoint
@@ -54,6 +58,9 @@ func (c *CodeforcesCrawler) Crawl(ctx context.Context) ([]*model.Contest, error)
 		return nil, fmt.Errorf("Codeforces API returned status code: %d", resp.StatusCode())
 	}
 
+	_, span := tracer.Start(ctx, "crawler.parse", trace.WithAttributes(attribute.String("platform", c.Name())))
+	defer span.End()
+
 	if response.Status != "OK" {
 		return nil, fmt.Errorf("Codeforces API error: %s", response.Comment)
 	}
@@ -74,6 +81,11 @@ func (c *CodeforcesCrawler) Crawl(ctx context.Context) ([]*model.Contest, error)
 
 		startTime := time.Unix(contest.StartTimeSeconds, 0)
 		endTime := startTime.Add(time.Duration(contest.DurationSeconds) * time.Second)
+		if err := validateContest(contest.Name, startTime, endTime); err != nil {
+			recordParseError(ctx, c.Name(), fmt.Errorf("invalid Codeforces contest %d: %w", contest.ID, err))
+			continue
+		}
+		recordParsed(ctx)
 
 		// 确定比赛状态
 		status := "upcoming"
@@ -85,7 +97,7 @@ func (c *CodeforcesCrawler) Crawl(ctx context.Context) ([]*model.Contest, error)
 
 		contests = append(contests, &model.Contest{
 			Name:            contest.Name,
-			Platform:        "Codeforces",
+			Platform:        c.Name(),
 			StartTime:       startTime,
 			EndTime:         endTime,
 			DurationSeconds: contest.DurationSeconds,
@@ -96,6 +108,7 @@ func (c *CodeforcesCrawler) Crawl(ctx context.Context) ([]*model.Contest, error)
 		})
 	}
 
+	span.SetAttributes(attribute.Int("contests", len(contests)))
 	return contests, nil
 }
 
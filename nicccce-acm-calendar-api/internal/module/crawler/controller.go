@@ -1,60 +1,65 @@
 package crawler
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"strconv"
 	"time"
 
+	"nicccce-acm-calendar-api/internal/global/cache"
 	"nicccce-acm-calendar-api/internal/global/database"
+	"nicccce-acm-calendar-api/internal/global/i18n"
+	"nicccce-acm-calendar-api/internal/global/platform"
 	"nicccce-acm-calendar-api/internal/global/response"
+	"nicccce-acm-calendar-api/internal/global/timezone"
 	"nicccce-acm-calendar-api/internal/model"
 
+	"github.com/gin-contrib/sse"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-// GetContests 获取比赛列表
+// GetContests 获取比赛列表，传入 q 时按名称搜索
 func (m *ModuleCrawler) GetContests(c *gin.Context) {
-	var contests []model.Contest
-	query := database.DB.Order("start_time ASC")
+	if c.Query("q") != "" {
+		m.SearchContests(c)
+		return
+	}
 
-	// 时间过滤：只显示未来30天内的比赛
-	startTime := c.Query("start_time")
-	endTime := c.Query("end_time")
-	
-	if startTime == "" {
-		// 默认显示从现在开始30天内的比赛
-		startTime = time.Now().Format("2006-01-02")
+	loc, from, to, ok := parseTimeParams(c)
+	if !ok {
+		return
 	}
-	if endTime == "" {
-		endTime = time.Now().AddDate(0, 0, 30).Format("2006-01-02")
+
+	// 时间过滤：默认显示从今天开始30天内的比赛
+	if from.IsZero() {
+		now := time.Now().In(loc)
+		from = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	}
+	if to.IsZero() {
+		to = from.AddDate(0, 0, 30)
 	}
 
-	query = query.Where("start_time >= ? AND start_time <= ?", startTime, endTime)
+	query := whereStartTime(database.DB, from, to)
 
 	// 平台过滤
-	if platform := c.Query("platform"); platform != "" {
-		query = query.Where("platform = ?", platform)
+	var slug string
+	if name := c.Query("platform"); name != "" {
+		slug = platform.Normalize(name)
+		query = query.Where("platform = ?", slug)
 	}
 
 	// 状态过滤
-	if status := c.Query("status"); status != "" {
-		query = query.Where("status = ?", status)
+	status := c.Query("status")
+	if status != "" {
+		query = whereStatus(query, status, time.Now())
 	}
 
-	if err := query.Find(&contests).Error; err != nil {
-		response.Fail(c, response.ErrServerInternal)
-		return
-	}
-
-	// 转换为DTO
-	var contestDtos []model.ContestDto
-	for _, contest := range contests {
-		contestDto := contest.ToDto()
-		contestDto.TimeRemaining = getTimeRemaining(contest.StartTime, contest.EndTime)
-		contestDtos = append(contestDtos, contestDto)
-	}
-
-	response.Success(c, contestDtos)
+	listContests(c, query, "start_time", loc, cacheScope{
+		key:  fmt.Sprintf("contests?from=%d&to=%d&platform=%s&status=%s", from.Unix(), to.Unix(), slug, status),
+		tags: platformTags(slug),
+	})
 }
 
 // GetContestByID 根据ID获取比赛
@@ -65,122 +70,154 @@ func (m *ModuleCrawler) GetContestByID(c *gin.Context) {
 		return
 	}
 
-	var contest model.Contest
-	if err := database.DB.First(&contest, id).Error; err != nil {
+	loc, err := timezone.FromRequest(c)
+	if err != nil {
+		response.Fail(c, response.ErrInvalidRequest.WithTips(err.Error()))
+		return
+	}
+
+	contest, digest, err := cache.Load(c.Request.Context(), fmt.Sprintf("contest?id=%d", id), []string{contestTag(uint(id))},
+		func() (model.Contest, error) {
+			var contest model.Contest
+			err := database.DB.First(&contest, id).Error
+			return contest, err
+		})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
 		response.Fail(c, response.ErrNotFound)
 		return
 	}
+	if err != nil {
+		response.Fail(c, response.ErrServerInternal.WithOrigin(err))
+		return
+	}
 
-	contestDto := contest.ToDto()
-	contestDto.TimeRemaining = getTimeRemaining(contest.StartTime, contest.EndTime)
+	lang := i18n.FromRequest(c)
+	if notModified(c, digest, loc, lang) {
+		return
+	}
 
-	response.Success(c, contestDto)
+	response.Success(c, toContestDto(&contest, loc, lang))
 }
 
-// GetContestsByPlatform 根据平台获取比赛
+// GetContestsByPlatform 根据平台获取比赛，平台可以是标识、显示名称或别名，不区分大小写
 func (m *ModuleCrawler) GetContestsByPlatform(c *gin.Context) {
-	platform := c.Param("platform")
-	
-	var contests []model.Contest
-	if err := database.DB.
-		Where("platform = ?", platform).
-		Where("start_time >= ?", time.Now()).
-		Order("start_time ASC").
-		Find(&contests).Error; err != nil {
-		response.Fail(c, response.ErrServerInternal)
+	p, ok := platform.Lookup(c.Param("platform"))
+	if !ok {
+		response.Fail(c, response.ErrNotFound)
 		return
 	}
 
-	var contestDtos []model.ContestDto
-	for _, contest := range contests {
-		contestDto := contest.ToDto()
-		contestDto.TimeRemaining = getTimeRemaining(contest.StartTime, contest.EndTime)
-		contestDtos = append(contestDtos, contestDto)
+	loc, err := timezone.FromRequest(c)
+	if err != nil {
+		response.Fail(c, response.ErrInvalidRequest.WithTips(err.Error()))
+		return
 	}
 
-	response.Success(c, contestDtos)
+	query := database.DB.
+		Where("platform = ?", p.Slug).
+		Where("start_time >= ?", time.Now())
+
+	listContests(c, query, "start_time", loc, cacheScope{
+		key:  "contests/platform?platform=" + p.Slug,
+		tags: platformTags(p.Slug),
+	})
 }
 
 // GetContestsByStatus 根据状态获取比赛
 func (m *ModuleCrawler) GetContestsByStatus(c *gin.Context) {
 	status := c.Param("status")
-	
-	var contests []model.Contest
-	query := database.DB.Where("status = ?", status)
-	
-	// 对于已结束的比赛，限制数量
-	if status == "finished" {
-		query = query.Order("end_time DESC").Limit(50)
-	} else {
-		query = query.Order("start_time ASC")
-	}
-
-	if err := query.Find(&contests).Error; err != nil {
-		response.Fail(c, response.ErrServerInternal)
+
+	loc, err := timezone.FromRequest(c)
+	if err != nil {
+		response.Fail(c, response.ErrInvalidRequest.WithTips(err.Error()))
 		return
 	}
 
-	var contestDtos []model.ContestDto
-	for _, contest := range contests {
-		contestDto := contest.ToDto()
-		contestDto.TimeRemaining = getTimeRemaining(contest.StartTime, contest.EndTime)
-		contestDtos = append(contestDtos, contestDto)
+	query := whereStatus(database.DB, status, time.Now())
+
+	// 已结束的比赛默认按结束时间倒序，最近结束的在前
+	defaultSort := "start_time"
+	if status == model.ContestStatusFinished {
+		defaultSort = "-end_time"
 	}
 
-	response.Success(c, contestDtos)
+	listContests(c, query, defaultSort, loc, cacheScope{
+		key:  "contests/status?status=" + status,
+		tags: platformTags(""),
+	})
 }
 
-// RefreshAllPlatforms 刷新所有平台
+// RefreshAllPlatforms 创建刷新所有平台的异步任务
 func (m *ModuleCrawler) RefreshAllPlatforms(c *gin.Context) {
 	// 检查速率限制
-	userID := uint(1) // 暂时使用固定用户ID，后续可以集成用户系统
-	allowed, remaining, err := m.limiter.CheckRefreshLimit(userID, "all")
-	if err != nil {
-		response.Fail(c, response.ErrServerInternal)
-		return
-	}
-
-	if !allowed {
-		response.Fail(c, response.ErrServerInternal.WithTips("Rate limit exceeded", fmt.Sprintf("retry_after: %f", remaining.Seconds())))
+	if !allowRefresh(c, "all") {
 		return
 	}
 
-	results, err := m.service.RefreshAllPlatforms(c.Request.Context())
+	job, _, err := m.jobs.Enqueue(c.Request.Context(), "all")
 	if err != nil {
-		response.Fail(c, response.ErrServerInternal)
+		response.Fail(c, response.ErrServerInternal.WithOrigin(err))
 		return
 	}
 
-	response.Success(c, results)
+	response.Success(c, job)
 }
 
-// RefreshSinglePlatform 刷新单个平台
+// RefreshSinglePlatform 创建刷新单个平台的异步任务
 func (m *ModuleCrawler) RefreshSinglePlatform(c *gin.Context) {
-	platform := c.Param("platform")
-	
+	crawler, exists := GetCrawler(c.Param("platform"))
+	if !exists {
+		response.Fail(c, response.ErrNotFound)
+		return
+	}
+	slug := crawler.Name()
+
 	// 检查速率限制
-	userID := uint(1)
-	allowed, remaining, err := m.limiter.CheckRefreshLimit(userID, platform)
-	if err != nil {
-		response.Fail(c, response.ErrServerInternal)
+	if !allowRefresh(c, slug) {
 		return
 	}
 
-	if !allowed {
-		response.Fail(c, response.ErrServerInternal.WithTips("Rate limit exceeded", fmt.Sprintf("retry_after: %f", remaining.Seconds())))
+	job, _, err := m.jobs.Enqueue(c.Request.Context(), slug)
+	if err != nil {
+		response.Fail(c, response.ErrServerInternal.WithOrigin(err))
 		return
 	}
 
-	result, err := m.service.RefreshSinglePlatform(c.Request.Context(), platform)
+	response.Success(c, job)
+}
+
+// GetRefreshJob 查询刷新任务及各平台的刷新结果
+func (m *ModuleCrawler) GetRefreshJob(c *gin.Context) {
+	job, err := m.jobs.Get(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, ErrJobNotFound) {
+		response.Fail(c, response.ErrNotFound)
+		return
+	}
 	if err != nil {
-		response.Fail(c, response.ErrServerInternal)
+		response.Fail(c, response.ErrServerInternal.WithOrigin(err))
 		return
 	}
 
-	response.Success(c, result)
+	response.Success(c, job)
+}
+
+// RefreshStatus 刷新状态
+type RefreshStatus struct {
+	Logs     []model.ContestRefreshLog `json:"logs"`
+	Breakers map[string]BreakerStatus  `json:"breakers"`
+}
+
+// RateLimitInfo 创建刷新任务的速率限制信息
+type RateLimitInfo struct {
+	Current   int       `json:"current"`
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Window    string    `json:"window"`
+	ResetAt   time.Time `json:"reset_at"`
+	Platform  string    `json:"platform"`
 }
 
-// GetRefreshStatus 获取刷新状态
+// GetRefreshStatus 获取刷新状态，包括最近的刷新日志和各平台熔断器状态
 func (m *ModuleCrawler) GetRefreshStatus(c *gin.Context) {
 	logs, err := m.service.GetRecentRefreshLogs(10)
 	if err != nil {
@@ -188,38 +225,80 @@ func (m *ModuleCrawler) GetRefreshStatus(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, logs)
+	response.Success(c, RefreshStatus{
+		Logs:     logs,
+		Breakers: m.service.BreakerStatuses(),
+	})
 }
 
-// GetRateLimitInfo 获取速率限制信息
+// GetRateLimitInfo 获取创建刷新任务的速率限制信息
 func (m *ModuleCrawler) GetRateLimitInfo(c *gin.Context) {
-	userID := uint(1)
-	platform := c.Query("platform")
-	if platform == "" {
-		platform = "all"
+	scope := platform.Normalize(c.Query("platform"))
+	if scope == "" {
+		scope = "all"
 	}
 
-	current, limit, window, err := m.limiter.GetRefreshRateLimitInfo(userID, platform)
-	if err != nil {
-		response.Fail(c, response.ErrServerInternal)
+	result, ok := peekRefresh(c, scope)
+	if !ok {
+		response.Fail(c, response.ErrNotFound)
 		return
 	}
 
-	response.Success(c, gin.H{
-		"current": current,
-		"limit":   limit,
-		"window":  window.String(),
-		"platform": platform,
+	response.Success(c, RateLimitInfo{
+		Current:   result.Policy.Limit - result.Remaining,
+		Limit:     result.Policy.Limit,
+		Remaining: result.Remaining,
+		Window:    result.Policy.Window.String(),
+		ResetAt:   result.Reset,
+		Platform:  scope,
 	})
 }
 
+// StreamEvents 以SSE方式推送比赛变更与刷新进度事件
+func (m *ModuleCrawler) StreamEvents(c *gin.Context) {
+	sub := m.events.Subscribe()
+	defer m.events.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // 禁止nginx缓冲事件流
+
+	heartbeat := time.NewTicker(15 * time.Second)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-sub:
+			if !ok {
+				return false
+			}
+			c.Render(-1, sse.Event{
+				Id:    event.ID,
+				Event: string(event.Type),
+				Data:  event,
+			})
+			return true
+		case <-heartbeat.C:
+			// 注释行作为心跳，防止代理因空闲断开连接
+			_, err := w.Write([]byte(": ping\n\n"))
+			return err == nil
+		}
+	})
+}
+
+// ContestStat 某平台某状态的比赛数量
+type ContestStat struct {
+	Platform string `json:"platform"`
+	Status   string `json:"status"`
+	Count    int    `json:"count"`
+}
+
 // GetContestStats 获取比赛统计信息
 func (m *ModuleCrawler) GetContestStats(c *gin.Context) {
-	var stats []struct {
-		Platform string `json:"platform"`
-		Status   string `json:"status"`
-		Count    int    `json:"count"`
-	}
+	var stats []ContestStat
 
 	if err := database.DB.Model(&model.Contest{}).
 		Select("platform, status, COUNT(*) as count").
@@ -246,6 +325,17 @@ func (m *ModuleCrawler) GetRefreshLogs(c *gin.Context) {
 	response.Success(c, logs)
 }
 
+// GetCrawlerHealth 获取各平台爬虫的健康状态
+func (m *ModuleCrawler) GetCrawlerHealth(c *gin.Context) {
+	health, err := m.service.Health().Snapshot(c.Request.Context())
+	if err != nil {
+		response.Fail(c, response.ErrServerInternal.WithOrigin(err))
+		return
+	}
+
+	response.Success(c, health)
+}
+
 // DeleteContest 删除比赛
 func (m *ModuleCrawler) DeleteContest(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -254,34 +344,94 @@ func (m *ModuleCrawler) DeleteContest(c *gin.Context) {
 		return
 	}
 
+	var deleted []model.Contest
+	if err := database.DB.Where("id = ?", id).Find(&deleted).Error; err != nil {
+		response.Fail(c, response.ErrServerInternal)
+		return
+	}
 	if err := database.DB.Delete(&model.Contest{}, id).Error; err != nil {
 		response.Fail(c, response.ErrServerInternal)
 		return
 	}
+	invalidateContests(c.Request.Context(), deleted)
+
+	response.Success(c, gin.H{"message": i18n.T(i18n.FromRequest(c), "比赛已删除")})
+}
+
+// whereStatus 按比赛状态过滤
+// 状态按开始、结束时间判断，与 Contest.StatusAt 一致，不依赖状态列是否已经更新
+func whereStatus(query *gorm.DB, status string, now time.Time) *gorm.DB {
+	switch status {
+	case model.ContestStatusUpcoming:
+		return query.Where("start_time > ?", now)
+	case model.ContestStatusRunning:
+		return query.Where("start_time <= ? AND end_time >= ?", now, now)
+	case model.ContestStatusFinished:
+		return query.Where("end_time < ?", now)
+	default:
+		return query.Where("status = ?", status)
+	}
+}
+
+// parseTimeParams 解析请求的时区以及 start_time、end_time 参数，未传入的时间为零值
+// end_time 只有日期时包含当天全天；参数不合法时直接返回错误响应，ok 为 false
+func parseTimeParams(c *gin.Context) (loc *time.Location, from, to time.Time, ok bool) {
+	loc, err := timezone.FromRequest(c)
+	if err == nil {
+		if s := c.Query("start_time"); s != "" {
+			from, _, err = timezone.ParseTime(s, loc)
+		}
+	}
+	if err == nil {
+		if s := c.Query("end_time"); s != "" {
+			var dateOnly bool
+			to, dateOnly, err = timezone.ParseTime(s, loc)
+			if dateOnly {
+				to = to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+			}
+		}
+	}
+	if err != nil {
+		response.Fail(c, response.ErrInvalidRequest.WithTips(err.Error()))
+		return nil, time.Time{}, time.Time{}, false
+	}
+	return loc, from, to, true
+}
 
-	response.Success(c, gin.H{"message": "Contest deleted successfully"})
+// whereStartTime 按开始时间范围过滤，零值表示不限
+// 参数转换为本地时区，与写入时一致，SQLite 按字符串比较时间时才能得到正确结果
+func whereStartTime(query *gorm.DB, from, to time.Time) *gorm.DB {
+	if !from.IsZero() {
+		query = query.Where("start_time >= ?", from.In(time.Local))
+	}
+	if !to.IsZero() {
+		query = query.Where("start_time <= ?", to.In(time.Local))
+	}
+	return query
 }
 
-// getTimeRemaining 计算剩余时间
-func getTimeRemaining(startTime, endTime time.Time) string {
+// toContestDto 转换为 DTO，比赛时间输出为 loc 时区，平台名称和倒计时按 lang 本地化
+func toContestDto(contest *model.Contest, loc *time.Location, lang i18n.Lang) model.ContestDto {
 	now := time.Now()
-	
-	if now.Before(startTime) {
+	contestDto := contest.ToDto()
+	contestDto.PlatformName = platform.DisplayName(contest.Platform, lang)
+	contestDto.TimeRemaining = getTimeRemaining(lang, contest.StartTime, contest.EndTime, now)
+	contestDto.Countdown = contest.CountdownAt(now)
+	contestDto.In(loc)
+	return contestDto
+}
+
+// getTimeRemaining 计算剩余时间，精确到分钟
+func getTimeRemaining(lang i18n.Lang, startTime, endTime, now time.Time) string {
+	switch {
+	case now.Before(startTime):
 		// 比赛未开始
-		duration := startTime.Sub(now)
-		if duration.Hours() > 24 {
-			return fmt.Sprintf("%.0f天后开始", duration.Hours()/24)
-		}
-		return fmt.Sprintf("%.0f小时后开始", duration.Hours())
-	} else if now.After(endTime) {
+		return i18n.T(lang, "%s后开始", i18n.Duration(lang, startTime.Sub(now)))
+	case now.After(endTime):
 		// 比赛已结束
-		return "已结束"
-	} else {
+		return i18n.T(lang, "已结束")
+	default:
 		// 比赛进行中
-		duration := endTime.Sub(now)
-		if duration.Hours() > 24 {
-			return fmt.Sprintf("%.0f天后结束", duration.Hours()/24)
-		}
-		return fmt.Sprintf("%.0f小时后结束", duration.Hours())
+		return i18n.T(lang, "%s后结束", i18n.Duration(lang, endTime.Sub(now)))
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,166 @@
+package crawler
+
+import (
+	"net/http"
+
+	"nicccce-acm-calendar-api/internal/global/apikey"
+	"nicccce-acm-calendar-api/internal/global/openapi"
+	"nicccce-acm-calendar-api/internal/global/response"
+	"nicccce-acm-calendar-api/internal/model"
+)
+
+const (
+	tagContests = "contests"
+	tagRefresh  = "refresh"
+	tagAdmin    = "admin"
+)
+
+var (
+	// localeParams 比赛时间的时区和平台名称、倒计时的语言
+	localeParams = []openapi.Param{
+		{Name: "tz", Description: "IANA 时区名称，如 Asia/Shanghai；未传入时依次使用 X-Timezone 请求头、用户设置的默认时区和服务器时区"},
+		{Name: "lang", Enum: []string{"zh", "en"}, Description: "语言；未传入时按 Accept-Language 请求头"},
+	}
+	timeParams = []openapi.Param{
+		{Name: "start_time", Description: "开始时间下限，RFC 3339 时间或 YYYY-MM-DD 日期"},
+		{Name: "end_time", Description: "开始时间上限，只有日期时包含当天全天"},
+	}
+	filterParams = []openapi.Param{
+		{Name: "platform", Description: "平台标识、显示名称或别名，不区分大小写"},
+		{Name: "status", Enum: []string{model.ContestStatusUpcoming, model.ContestStatusRunning, model.ContestStatusFinished}, Description: "比赛状态"},
+	}
+	pageParams = []openapi.Param{
+		{Name: "page", Type: "integer", Description: "页码，从 1 开始；传入 cursor 时忽略"},
+		{Name: "page_size", Type: "integer", Description: "每页数量，最大 500"},
+	}
+	sortParams = []openapi.Param{
+		{Name: "sort", Description: "排序字段：start_time、end_time、duration 或 platform，加 - 前缀表示降序"},
+		{Name: "cursor", Description: "游标分页，传入上一页 meta.next_cursor"},
+	}
+)
+
+// params 合并多组参数
+func params(groups ...[]openapi.Param) []openapi.Param {
+	var all []openapi.Param
+	for _, g := range groups {
+		all = append(all, g...)
+	}
+	return all
+}
+
+// Docs 比赛、刷新和管理接口的文档
+func (m *ModuleCrawler) Docs() []openapi.Route {
+	read := apikey.ScopeContestsRead
+	return []openapi.Route{
+		{
+			Method: http.MethodGet, Path: "/contests", Tag: tagContests, Scope: read,
+			Summary:     "获取比赛列表",
+			Description: "默认返回从今天开始 30 天内的比赛；传入 q 时按名称搜索，响应与 /contests/search 相同",
+			Params:      params(timeParams, filterParams, sortParams, pageParams, localeParams, []openapi.Param{{Name: "q", Description: "搜索文本"}}),
+			Data:        []model.ContestDto{}, Meta: response.PageMeta{},
+		},
+		{
+			Method: http.MethodGet, Path: "/contests/search", Tag: tagContests, Scope: read,
+			Summary:     "搜索比赛",
+			Description: "按名称搜索比赛，不限时间范围，按相关度和时间远近排序",
+			Params:      params([]openapi.Param{{Name: "q", Required: true, Description: "搜索文本"}}, timeParams, filterParams, pageParams, localeParams),
+			Data:        []ContestSearchResult{}, Meta: response.PageMeta{},
+		},
+		{
+			Method: http.MethodGet, Path: "/contests/changes", Tag: tagContests, Scope: read,
+			Summary:     "增量同步比赛",
+			Description: "返回游标之后新增、更新和删除的比赛；不传 since 时为首次同步，has_more 为 true 时应立即用新游标继续同步",
+			Params: params([]openapi.Param{
+				{Name: "since", Description: "上次同步返回的 cursor"},
+				{Name: "page_size", Type: "integer", Description: "每次返回的最大数量，最大 500"},
+			}, localeParams),
+			Data: ContestChanges{},
+		},
+		{
+			Method: http.MethodGet, Path: "/contests/:id", Tag: tagContests, Scope: read,
+			Summary: "获取比赛详情",
+			Params:  params([]openapi.Param{{Name: "id", In: "path", Type: "integer", Description: "比赛 ID"}}, localeParams),
+			Data:    model.ContestDto{}, Errors: []int{http.StatusNotFound},
+		},
+		{
+			Method: http.MethodGet, Path: "/contests/platform/:platform", Tag: tagContests, Scope: read,
+			Summary: "获取平台的比赛",
+			Params:  params([]openapi.Param{{Name: "platform", In: "path", Description: "平台标识、显示名称或别名"}}, sortParams, pageParams, localeParams),
+			Data:    []model.ContestDto{}, Meta: response.PageMeta{}, Errors: []int{http.StatusNotFound},
+		},
+		{
+			Method: http.MethodGet, Path: "/contests/status/:status", Tag: tagContests, Scope: read,
+			Summary: "获取指定状态的比赛",
+			Params: params([]openapi.Param{{
+				Name: "status", In: "path", Description: "比赛状态",
+				Enum: []string{model.ContestStatusUpcoming, model.ContestStatusRunning, model.ContestStatusFinished},
+			}}, sortParams, pageParams, localeParams),
+			Data: []model.ContestDto{}, Meta: response.PageMeta{},
+		},
+		{
+			Method: http.MethodGet, Path: "/platforms", Tag: tagContests, Scope: read,
+			Summary: "获取支持的平台列表",
+			Params:  localeParams[1:],
+			Data:    []model.PlatformDto{},
+		},
+		{
+			Method: http.MethodGet, Path: "/events", Tag: tagContests, Scope: read,
+			Summary:     "订阅事件（SSE）",
+			Description: "以 Server-Sent Events 推送比赛变更与刷新进度事件，event 字段为事件类型，data 为事件 JSON",
+			Data:        Event{}, Raw: true, ContentType: "text/event-stream",
+		},
+		{
+			Method: http.MethodPost, Path: "/refresh", Tag: tagRefresh, Scope: apikey.ScopeRefresh,
+			Summary:     "刷新所有平台",
+			Description: "创建异步刷新任务并立即返回，已有未完成的任务时复用该任务",
+			Data:        RefreshJob{},
+		},
+		{
+			Method: http.MethodPost, Path: "/refresh/:platform", Tag: tagRefresh, Scope: apikey.ScopeRefresh,
+			Summary: "刷新单个平台",
+			Params:  []openapi.Param{{Name: "platform", In: "path", Description: "平台标识"}},
+			Data:    RefreshJob{}, Errors: []int{http.StatusNotFound},
+		},
+		{
+			Method: http.MethodGet, Path: "/refresh/status", Tag: tagRefresh, Scope: apikey.ScopeRefresh,
+			Summary: "获取刷新状态",
+			Data:    RefreshStatus{},
+		},
+		{
+			Method: http.MethodGet, Path: "/refresh/jobs/:id", Tag: tagRefresh, Scope: apikey.ScopeRefresh,
+			Summary: "查询刷新任务",
+			Params:  []openapi.Param{{Name: "id", In: "path", Description: "任务 ID"}},
+			Data:    RefreshJob{}, Errors: []int{http.StatusNotFound},
+		},
+		{
+			Method: http.MethodGet, Path: "/refresh/limit", Tag: tagRefresh, Scope: apikey.ScopeRefresh,
+			Summary: "获取刷新速率限制",
+			Params:  []openapi.Param{{Name: "platform", Description: "平台标识，默认为 all"}},
+			Data:    RateLimitInfo{}, Errors: []int{http.StatusNotFound},
+		},
+		{
+			Method: http.MethodGet, Path: "/admin/contests/stats", Tag: tagAdmin, Scope: apikey.ScopeAdmin, Auth: true,
+			Summary: "获取最近三个月各平台、各状态的比赛数量",
+			Data:    []ContestStat{},
+		},
+		{
+			Method: http.MethodGet, Path: "/admin/contests/logs", Tag: tagAdmin, Scope: apikey.ScopeAdmin, Auth: true,
+			Summary: "获取刷新日志",
+			Params:  []openapi.Param{{Name: "limit", Type: "integer", Description: "返回数量，默认 50"}},
+			Data:    []model.ContestRefreshLog{},
+		},
+		{
+			Method: http.MethodDelete, Path: "/admin/contests/:id", Tag: tagAdmin, Scope: apikey.ScopeAdmin, Auth: true,
+			Summary: "删除比赛",
+			Params:  []openapi.Param{{Name: "id", In: "path", Type: "integer", Description: "比赛 ID"}},
+			Data: struct {
+				Message string `json:"message"`
+			}{},
+		},
+		{
+			Method: http.MethodGet, Path: "/admin/crawlers/health", Tag: tagAdmin, Scope: apikey.ScopeAdmin, Auth: true,
+			Summary: "获取各平台爬虫的健康状态",
+			Data:    []PlatformHealth{},
+		},
+	}
+}
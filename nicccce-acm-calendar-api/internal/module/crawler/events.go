@@ -0,0 +1,160 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	redisclient "nicccce-acm-calendar-api/internal/global/redis"
+	"nicccce-acm-calendar-api/tools"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// eventChannel 是用于在多个API实例之间广播事件的Redis频道
+const eventChannel = "acm-calendar:events"
+
+// subscriberBuffer 每个订阅者的事件缓冲区大小，缓冲区满时丢弃事件以免阻塞广播
+const subscriberBuffer = 64
+
+type EventType string
+
+const (
+	EventContestCreated       EventType = "contest.created"
+	EventContestUpdated       EventType = "contest.updated"
+	EventContestStatusChanged EventType = "contest.status_changed"
+	EventContestStarted       EventType = "contest.started"
+	EventContestFinished      EventType = "contest.finished"
+
+	EventRefreshStarted EventType = "refresh.started"
+	EventRefreshFetched EventType = "refresh.fetched"
+	EventRefreshSaved   EventType = "refresh.saved"
+	EventRefreshFailed  EventType = "refresh.failed"
+)
+
+// Event 是推送给客户端的事件
+type Event struct {
+	ID        string    `json:"id"`
+	Type      EventType `json:"type"`
+	Platform  string    `json:"platform,omitempty"`
+	Data      any       `json:"data,omitempty"`
+	Timestamp int64     `json:"timestamp"`
+}
+
+// EventBroker 负责事件的发布与分发
+// 事件先发布到Redis频道，再由每个实例的订阅协程分发给本地订阅者，
+// 从而保证连接到任意实例的客户端都能收到全部事件
+type EventBroker struct {
+	mu          sync.RWMutex
+	subscribers map[chan Event]struct{}
+	pubsub      *redis.PubSub
+	cancel      context.CancelFunc
+}
+
+func NewEventBroker() *EventBroker {
+	return &EventBroker{
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+// Start 订阅Redis频道并开始分发事件，未配置Redis时事件只在本实例内分发
+func (b *EventBroker) Start() {
+	if !redisclient.Enabled() {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b.cancel = cancel
+	b.pubsub = redisclient.RedisClient.Subscribe(ctx, eventChannel)
+
+	go func() {
+		for msg := range b.pubsub.Channel() {
+			var event Event
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				log.Warn("Failed to decode event", "error", err)
+				continue
+			}
+			b.dispatch(event)
+		}
+	}()
+}
+
+// Close 取消Redis订阅并关闭所有本地订阅者
+func (b *EventBroker) Close() {
+	if b.cancel != nil {
+		b.cancel()
+	}
+	if b.pubsub != nil {
+		_ = b.pubsub.Close()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers {
+		close(ch)
+		delete(b.subscribers, ch)
+	}
+}
+
+// Publish 发布事件到所有实例
+func (b *EventBroker) Publish(ctx context.Context, eventType EventType, platform string, data any) {
+	event := Event{
+		ID:        tools.RandString(16),
+		Type:      eventType,
+		Platform:  platform,
+		Data:      data,
+		Timestamp: time.Now().UnixMilli(),
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.WarnContext(ctx, "Failed to encode event", "type", eventType, "error", err)
+		return
+	}
+
+	if !redisclient.Enabled() {
+		b.dispatch(event)
+		return
+	}
+
+	// 请求结束不应影响已产生事件的投递
+	ctx = context.WithoutCancel(ctx)
+	if err := redisclient.RedisClient.Publish(ctx, eventChannel, payload).Err(); err != nil {
+		// Redis不可用时至少保证本实例的订阅者能收到事件
+		log.WarnContext(ctx, "Failed to publish event, dispatching locally", "type", eventType, "error", err)
+		b.dispatch(event)
+	}
+}
+
+// Subscribe 注册一个本地订阅者
+func (b *EventBroker) Subscribe() chan Event {
+	ch := make(chan Event, subscriberBuffer)
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+	return ch
+}
+
+// Unsubscribe 注销本地订阅者
+func (b *EventBroker) Unsubscribe(ch chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subscribers[ch]; ok {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
+
+// dispatch 将事件分发给本实例的所有订阅者
+func (b *EventBroker) dispatch(event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// 慢速客户端丢弃事件，避免阻塞其他订阅者
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"nicccce-acm-calendar-api/config"
+	"nicccce-acm-calendar-api/internal/model"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	defaultMaxAttempts      = 3
+	defaultRetryBaseDelay   = 500 * time.Millisecond
+	defaultRetryMaxDelay    = 10 * time.Second
+	defaultBreakerThreshold = 3
+	defaultBreakerCooldown  = 10 * time.Minute
+)
+
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// ErrCircuitOpen 熔断器打开，暂不请求该平台
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreaker 单个平台的熔断器
+// 连续失败达到阈值后打开，冷却期内直接拒绝请求；冷却结束后进入半开状态，
+// 放行一次探测请求，成功则关闭，失败则重新打开
+type CircuitBreaker struct {
+	platform  string
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// BreakerStatus 熔断器状态快照
+type BreakerStatus struct {
+	State    BreakerState `json:"state"`
+	Failures int          `json:"failures"`
+	// RetryAfter 熔断器打开时距离下次探测的秒数
+	RetryAfter float64 `json:"retry_after,omitempty"`
+}
+
+func newCircuitBreaker(platform string, threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		platform:  platform,
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     BreakerClosed,
+	}
+}
+
+// Allow 判断是否允许发起请求，不允许时返回距离下次探测的时长
+func (b *CircuitBreaker) Allow() (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if wait := b.cooldown - time.Since(b.openedAt); wait > 0 {
+			return false, wait
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		log.Info("Circuit breaker half-open, probing", "platform", b.platform)
+		return true, 0
+	case BreakerHalfOpen:
+		// 半开状态只放行一个探测请求
+		if b.probing {
+			return false, b.cooldown
+		}
+		b.probing = true
+		return true, 0
+	default:
+		return true, 0
+	}
+}
+
+// Success 记录一次成功的刷新
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != BreakerClosed {
+		log.Info("Circuit breaker closed", "platform", b.platform)
+	}
+	b.state = BreakerClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// Failure 记录一次失败的刷新
+func (b *CircuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		if b.state != BreakerOpen {
+			log.Warn("Circuit breaker opened", "platform", b.platform, "failures", b.failures, "cooldown", b.cooldown)
+		}
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// Cancel 刷新被取消时释放半开状态的探测名额，不计入成功或失败
+func (b *CircuitBreaker) Cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// Status 获取熔断器状态快照
+func (b *CircuitBreaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := BreakerStatus{State: b.state, Failures: b.failures}
+	if b.state == BreakerOpen {
+		if wait := b.cooldown - time.Since(b.openedAt); wait > 0 {
+			status.RetryAfter = wait.Seconds()
+		}
+	}
+	return status
+}
+
+// CrawlerExecutor 爬虫执行器，为 Crawler.Crawl 提供重试、指数退避和按平台熔断
+type CrawlerExecutor struct {
+	maxAttempts      int
+	baseDelay        time.Duration
+	maxDelay         time.Duration
+	breakerThreshold int
+	breakerCooldown  time.Duration
+
+	mu       sync.Mutex
+	breakers map[string]*CircuitBreaker
+}
+
+func NewCrawlerExecutor() *CrawlerExecutor {
+	cfg := config.Get().Crawler
+
+	e := &CrawlerExecutor{
+		maxAttempts:      cfg.MaxAttempts,
+		baseDelay:        time.Duration(cfg.RetryBaseDelay) * time.Millisecond,
+		maxDelay:         time.Duration(cfg.RetryMaxDelay) * time.Millisecond,
+		breakerThreshold: cfg.BreakerThreshold,
+		breakerCooldown:  time.Duration(cfg.BreakerCooldown) * time.Second,
+		breakers:         make(map[string]*CircuitBreaker),
+	}
+	if e.maxAttempts <= 0 {
+		e.maxAttempts = defaultMaxAttempts
+	}
+	if e.baseDelay <= 0 {
+		e.baseDelay = defaultRetryBaseDelay
+	}
+	if e.maxDelay <= 0 {
+		e.maxDelay = defaultRetryMaxDelay
+	}
+	if e.breakerThreshold <= 0 {
+		e.breakerThreshold = defaultBreakerThreshold
+	}
+	if e.breakerCooldown <= 0 {
+		e.breakerCooldown = defaultBreakerCooldown
+	}
+	return e
+}
+
+// Execute 执行爬取，失败时按指数退避重试，返回爬取结果和实际尝试次数
+// 熔断器按刷新计数：重试全部失败后只记录一次失败，半开状态下整次刷新作为一次探测；
+// 因停机或锁丢失被取消的刷新不说明平台异常，不计入失败
+func (e *CrawlerExecutor) Execute(ctx context.Context, platform string, crawler Crawler) ([]*model.Contest, int, error) {
+	breaker := e.Breaker(platform)
+
+	allowed, wait := breaker.Allow()
+	if !allowed {
+		return nil, 0, fmt.Errorf("%w, retry after %s", ErrCircuitOpen, wait.Round(time.Second))
+	}
+
+	contests, attempts, err := e.retry(ctx, platform, crawler)
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled) {
+			breaker.Cancel()
+		} else {
+			breaker.Failure()
+		}
+		return nil, attempts, err
+	}
+	breaker.Success()
+	return contests, attempts, nil
+}
+
+// retry 按指数退避重试爬取，返回最后一次尝试的结果
+func (e *CrawlerExecutor) retry(ctx context.Context, platform string, crawler Crawler) ([]*model.Contest, int, error) {
+	var lastErr error
+	for attempt := 1; attempt <= e.maxAttempts; attempt++ {
+		contests, err := e.crawl(ctx, platform, crawler, attempt)
+		if err == nil {
+			return contests, attempt, nil
+		}
+		lastErr = err
+
+		// 调用方取消或超时时不再重试
+		if ctx.Err() != nil || attempt == e.maxAttempts {
+			return nil, attempt, err
+		}
+
+		delay := e.backoff(attempt)
+		log.WarnContext(ctx, "Crawl failed, retrying",
+			"platform", platform,
+			"attempt", attempt,
+			"max_attempts", e.maxAttempts,
+			"delay", delay,
+			"error", err,
+		)
+
+		select {
+		case <-ctx.Done():
+			return nil, attempt, err
+		case <-time.After(delay):
+		}
+	}
+	return nil, e.maxAttempts, lastErr
+}
+
+// crawl 执行一次爬取，每次尝试对应一个 span
+func (e *CrawlerExecutor) crawl(ctx context.Context, platform string, crawler Crawler, attempt int) ([]*model.Contest, error) {
+	ctx, span := tracer.Start(ctx, "crawler.crawl", trace.WithAttributes(
+		attribute.String("platform", platform),
+		attribute.Int("attempt", attempt),
+	))
+	defer span.End()
+
+	resetParseStats(ctx)
+	contests, err := crawler.Crawl(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return contests, err
+}
+
+// Breaker 获取平台的熔断器，不存在时创建
+func (e *CrawlerExecutor) Breaker(platform string) *CircuitBreaker {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	breaker, ok := e.breakers[platform]
+	if !ok {
+		breaker = newCircuitBreaker(platform, e.breakerThreshold, e.breakerCooldown)
+		e.breakers[platform] = breaker
+	}
+	return breaker
+}
+
+// BreakerStatuses 获取所有平台的熔断器状态
+func (e *CrawlerExecutor) BreakerStatuses() map[string]BreakerStatus {
+	statuses := make(map[string]BreakerStatus)
+	for name := range GetAllCrawlers() {
+		statuses[name] = e.Breaker(name).Status()
+	}
+	return statuses
+}
+
+// backoff 计算第 attempt 次失败后的等待时长：指数增长并加入随机抖动，避免多个实例同时重试
+func (e *CrawlerExecutor) backoff(attempt int) time.Duration {
+	delay := e.baseDelay << (attempt - 1)
+	if delay <= 0 || delay > e.maxDelay {
+		delay = e.maxDelay
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
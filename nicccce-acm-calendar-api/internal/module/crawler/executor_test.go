@@ -0,0 +1,83 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"nicccce-acm-calendar-api/internal/model"
+)
+
+type stubCrawler struct {
+	err error
+}
+
+func (s stubCrawler) Name() string { return "stub" }
+
+func (s stubCrawler) Crawl(ctx context.Context) ([]*model.Contest, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return nil, s.err
+}
+
+// discardLog 测试中丢弃模块日志，log 通常在模块初始化时创建
+func discardLog(t *testing.T) {
+	previous := log
+	log = slog.New(slog.DiscardHandler)
+	t.Cleanup(func() { log = previous })
+}
+
+func TestExecuteBreakerCounting(t *testing.T) {
+	discardLog(t)
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		err          error
+		wantFailures int
+	}{
+		{"success", context.Background(), nil, 0},
+		{"crawl error", context.Background(), errors.New("boom"), 1},
+		// 停机或锁丢失导致的取消不说明平台异常
+		{"canceled", canceled, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &CrawlerExecutor{
+				maxAttempts:      1,
+				baseDelay:        time.Millisecond,
+				maxDelay:         time.Millisecond,
+				breakerThreshold: 3,
+				breakerCooldown:  time.Minute,
+				breakers:         make(map[string]*CircuitBreaker),
+			}
+			e.Execute(tt.ctx, "stub", stubCrawler{err: tt.err})
+			if got := e.Breaker("stub").Status().Failures; got != tt.wantFailures {
+				t.Fatalf("failures = %d, want %d", got, tt.wantFailures)
+			}
+		})
+	}
+}
+
+func TestBreakerCancelReleasesProbe(t *testing.T) {
+	discardLog(t)
+	b := newCircuitBreaker("stub", 1, 0)
+	b.Failure()
+
+	if allowed, _ := b.Allow(); !allowed {
+		t.Fatalf("first probe not allowed")
+	}
+	b.Cancel()
+	if allowed, _ := b.Allow(); !allowed {
+		t.Fatalf("probe not allowed after canceled probe")
+	}
+	if got := b.Status().State; got != BreakerHalfOpen {
+		t.Fatalf("state = %s, want %s", got, BreakerHalfOpen)
+	}
+}
@@ -0,0 +1,383 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"nicccce-acm-calendar-api/config"
+	"nicccce-acm-calendar-api/internal/global/database"
+	"nicccce-acm-calendar-api/internal/global/notify"
+	redisclient "nicccce-acm-calendar-api/internal/global/redis"
+	"nicccce-acm-calendar-api/internal/model"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// crawlerHealthKey 各平台健康状态，存储于 Redis 以便所有实例共享
+	crawlerHealthKey = "crawler:health"
+
+	// baselineAlpha 历史基线（指数加权平均）的平滑系数
+	baselineAlpha = 0.3
+	// baselineSeedSize 初始化基线时参考的最近成功刷新次数
+	baselineSeedSize = 10
+	// minBaseline 基线低于该值时不做数量异常判断，避免比赛本就稀少的平台误报
+	minBaseline = 3
+
+	defaultHealthFailureThreshold = 3
+	defaultHealthDegradedRatio    = 0.3
+	defaultHealthParseErrorRate   = 0.2
+	defaultHealthStaleHours       = 48
+)
+
+type HealthStatus string
+
+const (
+	HealthUnknown  HealthStatus = "unknown"
+	HealthHealthy  HealthStatus = "healthy"
+	HealthDegraded HealthStatus = "degraded"
+	HealthFailing  HealthStatus = "failing"
+)
+
+// PlatformHealth 单个平台的健康状态
+type PlatformHealth struct {
+	Platform            string       `json:"platform"`
+	Status              HealthStatus `json:"status"`
+	Reasons             []string     `json:"reasons,omitempty"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	LastError           string       `json:"last_error,omitempty"`
+	LastSuccessAt       *time.Time   `json:"last_success_at,omitempty"`
+	LastFailureAt       *time.Time   `json:"last_failure_at,omitempty"`
+	SinceLastSuccess    float64      `json:"since_last_success,omitempty"` // 距上次成功的秒数
+	LastCount           int          `json:"last_count"`
+	BaselineCount       float64      `json:"baseline_count"`
+	ParseErrors         int          `json:"parse_errors"`
+	ParseErrorRate      float64      `json:"parse_error_rate"`
+}
+
+// parseStats 单次爬取的解析统计，通过 context 传递给爬虫
+type parseStats struct {
+	mu     sync.Mutex
+	parsed int
+	errors int
+}
+
+type parseStatsKey struct{}
+
+// withParseStats 为本次爬取创建解析统计
+func withParseStats(ctx context.Context) (context.Context, *parseStats) {
+	stats := &parseStats{}
+	return context.WithValue(ctx, parseStatsKey{}, stats), stats
+}
+
+// recordParsed 记录成功解析的一条比赛
+func recordParsed(ctx context.Context) {
+	if stats, ok := ctx.Value(parseStatsKey{}).(*parseStats); ok {
+		stats.mu.Lock()
+		stats.parsed++
+		stats.mu.Unlock()
+	}
+}
+
+// recordParseError 记录一条解析失败的比赛，页面结构变化时这类错误会大量出现
+func recordParseError(ctx context.Context, platform string, err error) {
+	log.WarnContext(ctx, "Failed to parse contest", "platform", platform, "error", err)
+	if stats, ok := ctx.Value(parseStatsKey{}).(*parseStats); ok {
+		stats.mu.Lock()
+		stats.errors++
+		stats.mu.Unlock()
+	}
+}
+
+// resetParseStats 重试前清空上一次尝试的统计，只保留最后一次尝试的结果
+func resetParseStats(ctx context.Context) {
+	if stats, ok := ctx.Value(parseStatsKey{}).(*parseStats); ok {
+		stats.mu.Lock()
+		stats.parsed, stats.errors = 0, 0
+		stats.mu.Unlock()
+	}
+}
+
+// validateContest 检查接口返回的比赛名称和起止时间，字段缺失通常意味着接口格式发生了变化
+func validateContest(name string, startTime, endTime time.Time) error {
+	switch {
+	case name == "":
+		return errors.New("contest name is empty")
+	case startTime.Unix() <= 0:
+		return errors.New("contest start time is missing")
+	case !endTime.After(startTime):
+		return fmt.Errorf("contest end time %s is not after start time %s", endTime.Format(time.RFC3339), startTime.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func (p *parseStats) snapshot() (parsed, errors int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.parsed, p.errors
+}
+
+// HealthMonitor 跟踪各平台爬虫的健康状态，在状态恶化或恢复时发送告警
+// 爬虫在页面结构变化后往往不会报错而是返回空结果，因此除了失败次数外，
+// 还会对比历史基线判断爬取数量是否异常偏少
+type HealthMonitor struct {
+	failureThreshold int
+	degradedRatio    float64
+	parseErrorRate   float64
+	staleAfter       time.Duration
+
+	mu sync.Mutex
+}
+
+func NewHealthMonitor() *HealthMonitor {
+	cfg := config.Get().Crawler
+
+	m := &HealthMonitor{
+		failureThreshold: cfg.HealthFailureThreshold,
+		degradedRatio:    cfg.HealthDegradedRatio,
+		parseErrorRate:   cfg.HealthParseErrorRate,
+		staleAfter:       time.Duration(cfg.HealthStaleHours) * time.Hour,
+	}
+	if m.failureThreshold <= 0 {
+		m.failureThreshold = defaultHealthFailureThreshold
+	}
+	if m.degradedRatio <= 0 {
+		m.degradedRatio = defaultHealthDegradedRatio
+	}
+	if m.parseErrorRate <= 0 {
+		m.parseErrorRate = defaultHealthParseErrorRate
+	}
+	if m.staleAfter <= 0 {
+		m.staleAfter = defaultHealthStaleHours * time.Hour
+	}
+	return m
+}
+
+// Record 根据一次刷新的结果更新平台健康状态
+func (m *HealthMonitor) Record(ctx context.Context, result *RefreshResult, count int, stats *parseStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	health, err := m.load(ctx, result.Platform)
+	if err != nil {
+		log.Warn("Failed to load crawler health", "platform", result.Platform, "error", err)
+		return
+	}
+	previous := health.Status
+
+	now := time.Now()
+	if result.Status == "failed" {
+		health.ConsecutiveFailures++
+		health.LastError = result.Message
+		health.LastFailureAt = &now
+	} else {
+		health.ConsecutiveFailures = 0
+		health.LastError = ""
+		health.LastSuccessAt = &now
+		health.LastCount = count
+
+		parsed, parseErrors := stats.snapshot()
+		health.ParseErrors = parseErrors
+		health.ParseErrorRate = 0
+		if total := parsed + parseErrors; total > 0 {
+			health.ParseErrorRate = float64(parseErrors) / float64(total)
+		}
+
+		// 先用旧基线判断本次是否异常，再更新基线；数量异常时不计入基线，避免基线被拉低
+		anomalous := m.countAnomalous(health)
+		if health.BaselineCount == 0 {
+			health.BaselineCount = m.seedBaseline(ctx, result.Platform, count)
+		} else if !anomalous {
+			health.BaselineCount = baselineAlpha*float64(count) + (1-baselineAlpha)*health.BaselineCount
+		}
+	}
+
+	m.evaluate(health, now)
+	if err := m.save(ctx, health); err != nil {
+		log.Warn("Failed to save crawler health", "platform", result.Platform, "error", err)
+	}
+	m.alertOnTransition(ctx, previous, health)
+}
+
+// Check 重新评估所有平台（如距上次成功是否过久），由定时任务调用
+func (m *HealthMonitor) Check(ctx context.Context) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for name := range GetAllCrawlers() {
+		health, err := m.load(ctx, name)
+		if err != nil {
+			log.Warn("Failed to load crawler health", "platform", name, "error", err)
+			continue
+		}
+		previous := health.Status
+		m.evaluate(health, now)
+		if err := m.save(ctx, health); err != nil {
+			log.Warn("Failed to save crawler health", "platform", name, "error", err)
+		}
+		m.alertOnTransition(ctx, previous, health)
+	}
+}
+
+// Snapshot 获取所有平台的健康状态
+func (m *HealthMonitor) Snapshot(ctx context.Context) ([]*PlatformHealth, error) {
+	now := time.Now()
+	var list []*PlatformHealth
+	for name := range GetAllCrawlers() {
+		health, err := m.load(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		m.evaluate(health, now)
+		list = append(list, health)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Platform < list[j].Platform
+	})
+	return list, nil
+}
+
+// evaluate 根据当前指标计算健康状态及原因
+func (m *HealthMonitor) evaluate(health *PlatformHealth, now time.Time) {
+	health.Reasons = nil
+	health.SinceLastSuccess = 0
+	if health.LastSuccessAt != nil {
+		health.SinceLastSuccess = now.Sub(*health.LastSuccessAt).Seconds()
+	}
+
+	if health.LastSuccessAt == nil && health.LastFailureAt == nil {
+		health.Status = HealthUnknown
+		return
+	}
+
+	if health.ConsecutiveFailures >= m.failureThreshold {
+		health.Reasons = append(health.Reasons, fmt.Sprintf("连续失败%d次", health.ConsecutiveFailures))
+	}
+	// 从未成功过的平台只按连续失败次数判断，避免首次失败就告警
+	if health.LastSuccessAt != nil && now.Sub(*health.LastSuccessAt) > m.staleAfter {
+		health.Reasons = append(health.Reasons, fmt.Sprintf("超过%s未成功刷新", m.staleAfter))
+	}
+	if len(health.Reasons) > 0 {
+		health.Status = HealthFailing
+		return
+	}
+
+	if m.countAnomalous(health) {
+		health.Reasons = append(health.Reasons, fmt.Sprintf("获取%d场比赛，远低于历史基线%.1f场", health.LastCount, health.BaselineCount))
+	}
+	if health.ParseErrorRate > m.parseErrorRate {
+		health.Reasons = append(health.Reasons, fmt.Sprintf("解析失败率%.0f%%", health.ParseErrorRate*100))
+	}
+	if len(health.Reasons) > 0 {
+		health.Status = HealthDegraded
+		return
+	}
+
+	health.Status = HealthHealthy
+}
+
+// countAnomalous 最近一次爬取的比赛数量是否远低于历史基线
+func (m *HealthMonitor) countAnomalous(health *PlatformHealth) bool {
+	return health.BaselineCount >= minBaseline &&
+		float64(health.LastCount) < health.BaselineCount*m.degradedRatio
+}
+
+// seedBaseline 使用最近的成功刷新日志初始化基线
+func (m *HealthMonitor) seedBaseline(ctx context.Context, platform string, count int) float64 {
+	var logs []model.ContestRefreshLog
+	if err := database.DB.WithContext(ctx).
+		Where("platform = ? AND status = ?", platform, "success").
+		Order("created_at DESC").
+		Limit(baselineSeedSize).
+		Find(&logs).Error; err != nil || len(logs) == 0 {
+		return float64(count)
+	}
+
+	var sum int
+	for _, l := range logs {
+		sum += l.FetchedCount
+	}
+	return float64(sum) / float64(len(logs))
+}
+
+// lastSuccess 最近一次成功刷新的时间，用于初始化没有健康记录的平台，如重新部署或使用进程内存储时重启
+func lastSuccess(ctx context.Context, platform string) *time.Time {
+	var l model.ContestRefreshLog
+	if err := database.DB.WithContext(ctx).
+		Where("platform = ? AND status = ?", platform, "success").
+		Order("created_at DESC").
+		Take(&l).Error; err != nil {
+		return nil
+	}
+	return &l.CreatedAt
+}
+
+// alertOnTransition 健康状态发生变化时发送告警
+func (m *HealthMonitor) alertOnTransition(ctx context.Context, previous HealthStatus, health *PlatformHealth) {
+	if previous == health.Status {
+		return
+	}
+
+	alert := notify.Alert{
+		Source: "crawler",
+		Fields: map[string]any{
+			"platform":             health.Platform,
+			"previous_status":      previous,
+			"status":               health.Status,
+			"consecutive_failures": health.ConsecutiveFailures,
+			"last_count":           health.LastCount,
+			"baseline_count":       health.BaselineCount,
+			"parse_error_rate":     health.ParseErrorRate,
+			"last_error":           health.LastError,
+		},
+	}
+	switch health.Status {
+	case HealthFailing:
+		alert.Level = notify.LevelCritical
+		alert.Title = fmt.Sprintf("%s 爬虫失败", health.Platform)
+	case HealthDegraded:
+		alert.Level = notify.LevelWarning
+		alert.Title = fmt.Sprintf("%s 爬虫数据异常", health.Platform)
+	case HealthHealthy:
+		if previous == HealthUnknown {
+			return
+		}
+		alert.Level = notify.LevelInfo
+		alert.Title = fmt.Sprintf("%s 爬虫已恢复", health.Platform)
+	default:
+		return
+	}
+	alert.Message = fmt.Sprintf("%v", health.Reasons)
+
+	notify.Send(ctx, alert)
+}
+
+func (m *HealthMonitor) load(ctx context.Context, platform string) (*PlatformHealth, error) {
+	data, err := redisclient.HGet(ctx, crawlerHealthKey, platform)
+	if errors.Is(err, redis.Nil) {
+		return &PlatformHealth{Platform: platform, Status: HealthUnknown, LastSuccessAt: lastSuccess(ctx, platform)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var health PlatformHealth
+	if err := json.Unmarshal([]byte(data), &health); err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+func (m *HealthMonitor) save(ctx context.Context, health *PlatformHealth) error {
+	data, err := json.Marshal(health)
+	if err != nil {
+		return err
+	}
+	return redisclient.HSet(ctx, crawlerHealthKey, health.Platform, string(data))
+}
@@ -0,0 +1,83 @@
+package crawler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"nicccce-acm-calendar-api/internal/global/database"
+	"nicccce-acm-calendar-api/internal/model"
+)
+
+func TestHealthEvaluate(t *testing.T) {
+	m := &HealthMonitor{failureThreshold: 3, degradedRatio: 0.5, parseErrorRate: 0.2, staleAfter: 24 * time.Hour}
+	now := time.Date(2024, 11, 20, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(-d)
+		return &t
+	}
+
+	tests := []struct {
+		name   string
+		health PlatformHealth
+		want   HealthStatus
+	}{
+		{"no refresh yet", PlatformHealth{}, HealthUnknown},
+		// 从未成功过的平台首次失败不直接判定为失败
+		{"first failure without success", PlatformHealth{ConsecutiveFailures: 1, LastFailureAt: at(0)}, HealthHealthy},
+		{"failures reach threshold without success", PlatformHealth{ConsecutiveFailures: 3, LastFailureAt: at(0)}, HealthFailing},
+		{"recent success", PlatformHealth{LastSuccessAt: at(time.Hour)}, HealthHealthy},
+		{"failure after success", PlatformHealth{ConsecutiveFailures: 1, LastSuccessAt: at(time.Hour), LastFailureAt: at(0)}, HealthHealthy},
+		{"stale success", PlatformHealth{LastSuccessAt: at(25 * time.Hour)}, HealthFailing},
+		{"count anomalous", PlatformHealth{LastSuccessAt: at(time.Hour), LastCount: 1, BaselineCount: 20}, HealthDegraded},
+		{"parse errors", PlatformHealth{LastSuccessAt: at(time.Hour), ParseErrorRate: 0.5}, HealthDegraded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			health := tt.health
+			m.evaluate(&health, now)
+			if health.Status != tt.want {
+				t.Fatalf("status = %s (%v), want %s", health.Status, health.Reasons, tt.want)
+			}
+		})
+	}
+}
+
+// TestHealthLoadSeedsLastSuccess 没有健康记录时从刷新日志恢复上次成功的时间
+func TestHealthLoadSeedsLastSuccess(t *testing.T) {
+	db := newTestDB(t)
+	if err := db.AutoMigrate(&model.ContestRefreshLog{}); err != nil {
+		t.Fatal(err)
+	}
+	prev := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = prev })
+
+	success := time.Date(2024, 11, 20, 12, 0, 0, 0, time.UTC)
+	logs := []model.ContestRefreshLog{
+		{Model: model.Model{CreatedAt: success.Add(-time.Hour)}, Platform: "atcoder", Status: "success"},
+		{Model: model.Model{CreatedAt: success}, Platform: "atcoder", Status: "success"},
+		{Model: model.Model{CreatedAt: success.Add(time.Hour)}, Platform: "atcoder", Status: "failed"},
+		{Model: model.Model{CreatedAt: success.Add(time.Hour)}, Platform: "luogu", Status: "success"},
+	}
+	if err := db.Create(&logs).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	m := &HealthMonitor{}
+	health, err := m.load(context.Background(), "atcoder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if health.LastSuccessAt == nil || !health.LastSuccessAt.Equal(success) {
+		t.Fatalf("LastSuccessAt = %v, want %s", health.LastSuccessAt, success)
+	}
+
+	health, err = m.load(context.Background(), "leetcode")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if health.LastSuccessAt != nil {
+		t.Fatalf("LastSuccessAt = %v, want nil for a platform without successful refreshes", health.LastSuccessAt)
+	}
+}
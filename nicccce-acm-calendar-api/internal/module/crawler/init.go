@@ -2,9 +2,8 @@ package crawler
 
 import (
 	"context"
-	"nicccce-acm-calendar-api/internal/global/database"
+	"nicccce-acm-calendar-api/internal/global/platform"
 	"nicccce-acm-calendar-api/internal/model"
-	"time"
 )
 
 type Crawler interface {
@@ -18,8 +17,9 @@ func RegisterCrawler(c Crawler) {
 	crawlers[c.Name()] = c
 }
 
+// GetCrawler 获取平台的爬虫，name 可以是平台标识、显示名称或别名
 func GetCrawler(name string) (Crawler, bool) {
-	c, exists := crawlers[name]
+	c, exists := crawlers[platform.Normalize(name)]
 	return c, exists
 }
 
@@ -35,31 +35,3 @@ func InitCrawlers() {
 	RegisterCrawler(&NowCoderCrawler{})
 	RegisterCrawler(&LuoguCrawler{})
 }
-
-// UpdateContestStatus 更新比赛状态
-func UpdateContestStatus() error {
-	now := time.Now()
-	
-	// 更新进行中的比赛
-	if err := database.DB.Model(&model.Contest{}).
-		Where("start_time <= ? AND end_time >= ?", now, now).
-		Update("status", "running").Error; err != nil {
-		return err
-	}
-	
-	// 更新已结束的比赛
-	if err := database.DB.Model(&model.Contest{}).
-		Where("end_time < ?", now).
-		Update("status", "finished").Error; err != nil {
-		return err
-	}
-	
-	// 更新即将开始的比赛
-	if err := database.DB.Model(&model.Contest{}).
-		Where("start_time > ?", now).
-		Update("status", "upcoming").Error; err != nil {
-		return err
-	}
-	
-	return nil
-}
\ No newline at end of file
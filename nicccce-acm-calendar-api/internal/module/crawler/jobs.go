@@ -0,0 +1,396 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"nicccce-acm-calendar-api/config"
+	"nicccce-acm-calendar-api/internal/global/logger"
+	redisclient "nicccce-acm-calendar-api/internal/global/redis"
+	"nicccce-acm-calendar-api/tools"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	refreshQueueKey          = "refresh:queue"
+	refreshJobKeyPrefix      = "refresh:job:"
+	refreshInflightKeyPrefix = "refresh:inflight:"
+
+	// refreshJobTTL 任务结果的保留时间
+	refreshJobTTL = 24 * time.Hour
+	// refreshPollTimeout 工作协程阻塞等待新任务的超时时间，到期后检查是否需要退出
+	refreshPollTimeout = 5 * time.Second
+
+	// localQueueSize 未配置Redis时进程内队列的容量
+	localQueueSize = 64
+	// claimAttempts 写入进行中标记的最多尝试次数，标记被其他请求并发接管时重新读取
+	claimAttempts = 3
+
+	defaultJobWorkers      = 2
+	defaultPlatformTimeout = 2 * time.Minute
+)
+
+const (
+	JobStatusQueued   = "queued"
+	JobStatusRunning  = "running"
+	JobStatusFinished = "finished"
+)
+
+// ErrJobNotFound 任务不存在或已过期
+var ErrJobNotFound = errors.New("refresh job not found")
+
+// RefreshJob 异步刷新任务
+type RefreshJob struct {
+	ID         string                    `json:"id"`
+	Scope      string                    `json:"scope"`
+	RequestID  string                    `json:"request_id,omitempty"`
+	Status     string                    `json:"status"`
+	Results    map[string]*RefreshResult `json:"results"`
+	CreatedAt  time.Time                 `json:"created_at"`
+	StartedAt  *time.Time                `json:"started_at,omitempty"`
+	FinishedAt *time.Time                `json:"finished_at,omitempty"`
+}
+
+// RefreshJobQueue 基于Redis的刷新任务队列
+// 任务入队后立即返回任务ID，由各实例的工作协程异步执行；
+// 同一范围（全部平台或单个平台）已有未完成的任务时，新的请求会复用该任务。
+// 未配置Redis时任务和队列保存在进程内
+type RefreshJobQueue struct {
+	service *CrawlerService
+	workers int
+	timeout time.Duration
+
+	// flights 合并本实例内同一平台的并发爬取
+	flights singleflight.Group
+	// local 未配置Redis时使用的进程内队列
+	local chan string
+
+	// ctx 控制工作协程领取新任务，runCtx 控制正在执行的任务，停机超时后才会取消
+	ctx    context.Context
+	cancel context.CancelFunc
+	runCtx context.Context
+	abort  context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewRefreshJobQueue(service *CrawlerService) *RefreshJobQueue {
+	cfg := config.Get().Crawler
+
+	workers := cfg.JobWorkers
+	if workers <= 0 {
+		workers = defaultJobWorkers
+	}
+	timeout := time.Duration(cfg.PlatformTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultPlatformTimeout
+	}
+
+	return &RefreshJobQueue{
+		service: service,
+		workers: workers,
+		timeout: timeout,
+		local:   make(chan string, localQueueSize),
+	}
+}
+
+// Start 启动工作协程
+func (q *RefreshJobQueue) Start() {
+	q.ctx, q.cancel = context.WithCancel(context.Background())
+	q.runCtx, q.abort = context.WithCancel(context.Background())
+	for i := 0; i < q.workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+}
+
+// Stop 停止领取新任务，并等待正在执行的任务结束
+// ctx 到期时中断正在执行的任务，未保存的数据随事务回滚，队列中的任务留给其他实例或重启后执行
+func (q *RefreshJobQueue) Stop(ctx context.Context) {
+	if q.cancel == nil {
+		return
+	}
+	q.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Warn("Refresh jobs did not finish in time, aborting")
+		q.abort()
+		<-done
+	}
+	q.abort()
+}
+
+// Enqueue 创建刷新任务，scope 为 "all" 或平台名称
+// 第二个返回值表示是否复用了已有的未完成任务
+func (q *RefreshJobQueue) Enqueue(ctx context.Context, scope string) (*RefreshJob, bool, error) {
+	job := &RefreshJob{
+		ID:        tools.RandString(16),
+		Scope:     scope,
+		RequestID: logger.RequestID(ctx),
+		Status:    JobStatusQueued,
+		Results:   make(map[string]*RefreshResult),
+		CreatedAt: time.Now(),
+	}
+	for _, platform := range q.platforms(scope) {
+		job.Results[platform] = &RefreshResult{Platform: platform, Status: "pending"}
+	}
+
+	// 先保存任务再写入进行中标记，其他请求看到标记时总能查询到对应的任务
+	if err := q.save(ctx, job); err != nil {
+		return nil, false, err
+	}
+
+	existing, err := q.claim(ctx, refreshInflightKeyPrefix+scope, job.ID)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing != nil {
+		// 复用已有任务，丢弃刚保存但不会执行的任务
+		if err := redisclient.Del(ctx, refreshJobKeyPrefix+job.ID); err != nil {
+			log.WarnContext(ctx, "Failed to delete unused refresh job", "job_id", job.ID, "error", err)
+		}
+		return existing, true, nil
+	}
+
+	if err := q.push(ctx, job.ID); err != nil {
+		return nil, false, err
+	}
+
+	log.InfoContext(ctx, "Refresh job enqueued", "job_id", job.ID, "scope", scope)
+	return job, false, nil
+}
+
+// claim 将进行中标记写为 id，已有未完成的任务时返回该任务，写入成功时返回 nil
+// 标记残留但任务已结束或过期时按原值比较并替换，并发请求中只有一个能接管标记
+func (q *RefreshJobQueue) claim(ctx context.Context, key, id string) (*RefreshJob, error) {
+	for range claimAttempts {
+		ok, err := redisclient.SetNX(ctx, key, id, q.inflightTTL())
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return nil, nil
+		}
+
+		existingID, err := redisclient.Get(ctx, key)
+		if errors.Is(err, redis.Nil) {
+			// 标记刚好过期，重新写入
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		existing, err := q.Get(ctx, existingID)
+		if err == nil && existing.Status != JobStatusFinished {
+			return existing, nil
+		}
+		if err != nil && !errors.Is(err, ErrJobNotFound) {
+			return nil, err
+		}
+
+		ok, err = redisclient.CompareAndSwap(ctx, key, existingID, id, q.inflightTTL())
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return nil, nil
+		}
+		// 标记已被其他请求接管，重新读取
+	}
+	return nil, fmt.Errorf("failed to claim %s: marker keeps changing", key)
+}
+
+// Get 查询任务
+func (q *RefreshJobQueue) Get(ctx context.Context, id string) (*RefreshJob, error) {
+	data, err := redisclient.Get(ctx, refreshJobKeyPrefix+id)
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var job RefreshJob
+	if err := json.Unmarshal([]byte(data), &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// work 工作协程主循环
+func (q *RefreshJobQueue) work() {
+	defer q.wg.Done()
+
+	for {
+		select {
+		case <-q.ctx.Done():
+			return
+		default:
+		}
+
+		id, err := q.pop()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if q.ctx.Err() != nil {
+				return
+			}
+			log.Warn("Failed to poll refresh queue", "error", err)
+			time.Sleep(refreshPollTimeout)
+			continue
+		}
+
+		q.run(id)
+	}
+}
+
+// run 执行单个任务，各平台并发刷新，每完成一个平台就更新一次任务状态
+func (q *RefreshJobQueue) run(id string) {
+	job, err := q.Get(context.Background(), id)
+	if err != nil {
+		log.Warn("Failed to load refresh job", "job_id", id, "error", err)
+		return
+	}
+	// 沿用入队请求的请求ID，便于将工作协程中的日志与原始请求关联
+	// 爬取使用可被停机中断的 runCtx，任务状态的保存则不受中断影响
+	runCtx := logger.WithRequestID(q.runCtx, job.RequestID)
+	ctx := context.WithoutCancel(runCtx)
+
+	startedAt := time.Now()
+	job.Status = JobStatusRunning
+	job.StartedAt = &startedAt
+	if err := q.save(ctx, job); err != nil {
+		log.WarnContext(ctx, "Failed to save refresh job", "job_id", id, "error", err)
+	}
+
+	// 先取出平台列表，工作协程会在遍历期间写入 Results
+	platforms := make([]string, 0, len(job.Results))
+	for platform := range job.Results {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for _, platform := range platforms {
+		wg.Add(1)
+		go func(platform string) {
+			defer wg.Done()
+
+			result := q.refresh(runCtx, platform)
+
+			mu.Lock()
+			defer mu.Unlock()
+			job.Results[platform] = result
+			if err := q.save(ctx, job); err != nil {
+				log.WarnContext(ctx, "Failed to save refresh job", "job_id", id, "error", err)
+			}
+		}(platform)
+	}
+	wg.Wait()
+
+	finishedAt := time.Now()
+	job.Status = JobStatusFinished
+	job.FinishedAt = &finishedAt
+	if err := q.save(ctx, job); err != nil {
+		log.WarnContext(ctx, "Failed to save refresh job", "job_id", id, "error", err)
+	}
+	// 仅当去重标记仍属于当前任务时才删除，避免误删后续任务的标记
+	if _, err := redisclient.CompareAndDelete(ctx, refreshInflightKeyPrefix+job.Scope, job.ID); err != nil {
+		log.WarnContext(ctx, "Failed to release refresh job marker", "job_id", id, "error", err)
+	}
+
+	log.InfoContext(ctx, "Refresh job finished", "job_id", id, "scope", job.Scope, "duration", finishedAt.Sub(startedAt))
+}
+
+// refresh 为单个平台的刷新加上超时，本实例内同一平台的并发请求共享一次爬取
+func (q *RefreshJobQueue) refresh(ctx context.Context, platform string) *RefreshResult {
+	value, _, _ := q.flights.Do(platform, func() (any, error) {
+		ctx, cancel := context.WithTimeout(ctx, q.timeout)
+		defer cancel()
+
+		result, err := q.service.RefreshSinglePlatform(ctx, platform)
+		if result == nil {
+			result = &RefreshResult{Platform: platform, Status: "failed", Message: err.Error()}
+		}
+		return result, nil
+	})
+	return value.(*RefreshResult)
+}
+
+// platforms 返回任务范围内的所有平台
+func (q *RefreshJobQueue) platforms(scope string) []string {
+	if scope != "all" {
+		return []string{scope}
+	}
+
+	var names []string
+	for name := range GetAllCrawlers() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// inflightTTL 去重标记的过期时间，防止实例崩溃后标记永久残留
+func (q *RefreshJobQueue) inflightTTL() time.Duration {
+	return 2 * q.timeout
+}
+
+func (q *RefreshJobQueue) save(ctx context.Context, job *RefreshJob) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return redisclient.Set(ctx, refreshJobKeyPrefix+job.ID, string(data), refreshJobTTL)
+}
+
+// push 和 pop 封装队列用到的Redis列表命令，未配置Redis时改用进程内的channel
+
+func (q *RefreshJobQueue) push(ctx context.Context, id string) error {
+	if !redisclient.Enabled() {
+		select {
+		case q.local <- id:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return redisclient.RedisClient.LPush(ctx, refreshQueueKey, id).Err()
+}
+
+// pop 阻塞等待下一个任务，超时返回 redis.Nil
+func (q *RefreshJobQueue) pop() (string, error) {
+	if !redisclient.Enabled() {
+		select {
+		case id := <-q.local:
+			return id, nil
+		case <-q.ctx.Done():
+			return "", q.ctx.Err()
+		case <-time.After(refreshPollTimeout):
+			return "", redis.Nil
+		}
+	}
+
+	// BRPop 返回 [key, value]
+	values, err := redisclient.RedisClient.BRPop(q.ctx, refreshPollTimeout, refreshQueueKey).Result()
+	if err != nil {
+		return "", err
+	}
+	return values[1], nil
+}
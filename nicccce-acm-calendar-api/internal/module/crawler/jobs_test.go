@@ -0,0 +1,66 @@
+package crawler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	redisclient "nicccce-acm-calendar-api/internal/global/redis"
+)
+
+func TestClaimTakesOverFinishedJobOnce(t *testing.T) {
+	ctx := context.Background()
+	q := &RefreshJobQueue{timeout: time.Minute}
+	key := refreshInflightKeyPrefix + "test"
+	t.Cleanup(func() { redisclient.Del(ctx, key) })
+
+	finished := &RefreshJob{ID: "finished", Status: JobStatusFinished}
+	if err := q.save(ctx, finished); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := redisclient.Set(ctx, key, finished.ID, time.Minute); err != nil {
+		t.Fatalf("set marker: %v", err)
+	}
+
+	// 并发接管残留标记，只有一个请求写入标记，其余复用该请求的任务
+	const n = 8
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		claimed []string
+		reused  = make(map[string]int)
+	)
+	for i := range n {
+		job := &RefreshJob{ID: string(rune('a' + i)), Status: JobStatusQueued}
+		if err := q.save(ctx, job); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			existing, err := q.claim(ctx, key, job.ID)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				t.Errorf("claim: %v", err)
+			case existing == nil:
+				claimed = append(claimed, job.ID)
+			default:
+				reused[existing.ID]++
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(claimed) != 1 {
+		t.Fatalf("claimed = %v, want exactly one", claimed)
+	}
+	if reused[claimed[0]] != n-1 {
+		t.Fatalf("reused = %v, want %d reuses of %s", reused, n-1, claimed[0])
+	}
+	if marker, _ := redisclient.Get(ctx, key); marker != claimed[0] {
+		t.Fatalf("marker = %q, want %q", marker, claimed[0])
+	}
+}
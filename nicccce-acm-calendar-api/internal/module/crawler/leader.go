@@ -0,0 +1,113 @@
+package crawler
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"nicccce-acm-calendar-api/internal/global/metrics"
+	redisclient "nicccce-acm-calendar-api/internal/global/redis"
+)
+
+const (
+	// schedulerLeaderKey 调度器主节点锁
+	schedulerLeaderKey = "scheduler:leader"
+	// leaderLeaseTTL 主节点租约时长，主节点宕机后最多经过该时长由其他实例接管
+	leaderLeaseTTL = 15 * time.Second
+	// leaderRenewInterval 续约/竞选间隔
+	leaderRenewInterval = leaderLeaseTTL / 3
+)
+
+// LeaderElector 基于 Redis 租约的主节点选举
+// 多个实例竞争同一把锁，持有锁的实例为主节点并定期续约；
+// 续约失败即视为失去主节点身份，其他实例会在租约过期后接管
+type LeaderElector struct {
+	lock     *redisclient.Lock
+	isLeader atomic.Bool
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewLeaderElector(key string) *LeaderElector {
+	return &LeaderElector{
+		lock: redisclient.NewLock(key, leaderLeaseTTL),
+	}
+}
+
+// Start 开始参与选举，首轮竞选同步进行，返回后即可通过 IsLeader 判断身份
+func (e *LeaderElector) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+
+	e.tick(ctx)
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		ticker := time.NewTicker(leaderRenewInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				e.tick(ctx)
+			}
+		}
+	}()
+}
+
+// Stop 退出选举，若当前为主节点则主动释放租约以便其他实例尽快接管
+func (e *LeaderElector) Stop() {
+	if e.cancel != nil {
+		e.cancel()
+	}
+	e.wg.Wait()
+
+	if e.isLeader.Swap(false) {
+		metrics.SchedulerIsLeader.Set(0)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := e.lock.Release(ctx); err != nil {
+			log.Warn("Failed to release scheduler leadership", "error", err)
+		}
+		log.Info("Scheduler leadership released")
+	}
+}
+
+// IsLeader 当前实例是否为主节点
+func (e *LeaderElector) IsLeader() bool {
+	return e.isLeader.Load()
+}
+
+// tick 主节点续约，非主节点尝试竞选
+func (e *LeaderElector) tick(ctx context.Context) {
+	if e.isLeader.Load() {
+		ok, err := e.lock.Refresh(ctx)
+		if err != nil {
+			// Redis 暂时不可用时无法确认租约，保守地放弃主节点身份，避免与接管者同时运行任务
+			log.Warn("Failed to renew scheduler leadership", "error", err)
+		}
+		if !ok || err != nil {
+			e.isLeader.Store(false)
+			metrics.SchedulerIsLeader.Set(0)
+			log.Warn("Scheduler leadership lost")
+		}
+		return
+	}
+
+	ok, err := e.lock.Acquire(ctx)
+	if err != nil {
+		log.Warn("Failed to campaign for scheduler leadership", "error", err)
+		return
+	}
+	if ok {
+		e.isLeader.Store(true)
+		metrics.SchedulerIsLeader.Set(1)
+		log.Info("Scheduler leadership acquired")
+	}
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"nicccce-acm-calendar-api/internal/global/httpclient"
+	"nicccce-acm-calendar-api/internal/global/platform"
 	"nicccce-acm-calendar-api/internal/model"
 	"time"
 
 	"github.com/go-resty/resty/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type LeetCodeCrawler struct {
@@ -15,12 +19,12 @@ type LeetCodeCrawler struct {
 }
 
 func (c *LeetCodeCrawler) Name() string {
-	return "LeetCode"
+	return platform.LeetCode
 }
 
 func (c *LeetCodeCrawler) Crawl(ctx context.Context) ([]*model.Contest, error) {
 	if c.client == nil {
-		c.client = resty.New()
+		c.client = httpclient.New()
 	}
 
 	// 使用第三方API获取LeetCode比赛信息
@@ -38,6 +42,9 @@ func (c *LeetCodeCrawler) Crawl(ctx context.Context) ([]*model.Contest, error) {
 		return nil, fmt.Errorf("LeetCode API returned status code: %d", resp.StatusCode())
 	}
 
+	_, span := tracer.Start(ctx, "crawler.parse", trace.WithAttributes(attribute.String("platform", c.Name())))
+	defer span.End()
+
 	var contestsData []struct {
 		Oj              string `json:"oj"`
 		Name            string `json:"name"`
@@ -62,6 +69,11 @@ func (c *LeetCodeCrawler) Crawl(ctx context.Context) ([]*model.Contest, error) {
 		startTime := time.Unix(contestData.StartTimeStamp, 0)
 		endTime := time.Unix(contestData.EndTimeStamp, 0)
 		duration := contestData.EndTimeStamp - contestData.StartTimeStamp
+		if err := validateContest(contestData.Name, startTime, endTime); err != nil {
+			recordParseError(ctx, c.Name(), fmt.Errorf("invalid LeetCode contest %q: %w", contestData.Name, err))
+			continue
+		}
+		recordParsed(ctx)
 
 		// 跳过已经结束很久的比赛
 		if endTime.Before(now.AddDate(0, -3, 0)) {
@@ -78,7 +90,7 @@ func (c *LeetCodeCrawler) Crawl(ctx context.Context) ([]*model.Contest, error) {
 
 		contests = append(contests, &model.Contest{
 			Name:            contestData.Name,
-			Platform:        "LeetCode",
+			Platform:        c.Name(),
 			StartTime:       startTime,
 			EndTime:         endTime,
 			DurationSeconds: duration,
@@ -89,6 +101,7 @@ func (c *LeetCodeCrawler) Crawl(ctx context.Context) ([]*model.Contest, error) {
 		})
 	}
 
+	span.SetAttributes(attribute.Int("contests", len(contests)))
 	return contests, nil
 }
 
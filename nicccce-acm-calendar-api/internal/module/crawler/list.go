@@ -0,0 +1,268 @@
+package crawler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"nicccce-acm-calendar-api/internal/global/cache"
+	"nicccce-acm-calendar-api/internal/global/i18n"
+	"nicccce-acm-calendar-api/internal/global/response"
+	"nicccce-acm-calendar-api/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultPageSize 未指定 page_size 时每页的数量，足以容纳默认 30 天内的全部比赛
+	defaultPageSize = 100
+	maxPageSize     = 500
+)
+
+// contestSortField 比赛列表支持的排序字段
+type contestSortField struct {
+	column string
+	// value 取出比赛在该字段上的值，用于生成游标
+	value func(c *model.Contest) any
+	// decode 将游标中的值解析为可用于查询的类型
+	decode func(raw json.RawMessage) (any, error)
+}
+
+var contestSortFields = map[string]contestSortField{
+	"start_time": {
+		column: "start_time",
+		value:  func(c *model.Contest) any { return c.StartTime },
+		decode: decodeCursorValue[time.Time],
+	},
+	"end_time": {
+		column: "end_time",
+		value:  func(c *model.Contest) any { return c.EndTime },
+		decode: decodeCursorValue[time.Time],
+	},
+	"duration": {
+		column: "duration_seconds",
+		value:  func(c *model.Contest) any { return c.DurationSeconds },
+		decode: decodeCursorValue[int64],
+	},
+	"platform": {
+		column: "platform",
+		value:  func(c *model.Contest) any { return c.Platform },
+		decode: decodeCursorValue[string],
+	},
+}
+
+func decodeCursorValue[T any](raw json.RawMessage) (any, error) {
+	var v T
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// contestCursor 游标记录上一页最后一条比赛的排序值和ID，序列化后以 base64 编码返回给客户端
+type contestCursor struct {
+	Sort  string          `json:"s"`
+	Value json.RawMessage `json:"v"`
+	ID    uint            `json:"id"`
+}
+
+// contestList 比赛列表的分页和排序参数
+// 支持两种分页方式：page/page_size 偏移分页，以及基于 (排序字段, id) 的游标分页；
+// 传入 cursor 时忽略 page。游标分页在翻页期间有数据写入时不会重复或遗漏
+type contestList struct {
+	sort     string
+	field    contestSortField
+	desc     bool
+	page     int
+	pageSize int
+	cursor   *contestCursor
+	// rawCursor 请求中的游标原文，用作缓存键的一部分
+	rawCursor string
+}
+
+// parseContestList 解析 sort、page、page_size 和 cursor 参数
+// sort 为排序字段名，加 "-" 前缀表示降序，默认使用 defaultSort
+func parseContestList(c *gin.Context, defaultSort string) (*contestList, error) {
+	l := &contestList{
+		sort: c.DefaultQuery("sort", defaultSort),
+	}
+
+	key := strings.TrimPrefix(l.sort, "-")
+	field, ok := contestSortFields[key]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort: %s", l.sort)
+	}
+	l.field = field
+	l.desc = strings.HasPrefix(l.sort, "-")
+
+	page, pageSize, err := parsePage(c, defaultPageSize)
+	if err != nil {
+		return nil, err
+	}
+	l.page, l.pageSize = page, pageSize
+
+	if s := c.Query("cursor"); s != "" {
+		cursor, err := decodeContestCursor(s)
+		if err != nil {
+			return nil, errors.New("invalid cursor")
+		}
+		if _, given := c.GetQuery("sort"); given && cursor.Sort != l.sort {
+			return nil, errors.New("cursor does not match sort")
+		}
+		// 游标分页沿用生成游标时的排序方式
+		l.sort = cursor.Sort
+		l.field = contestSortFields[strings.TrimPrefix(cursor.Sort, "-")]
+		l.desc = strings.HasPrefix(cursor.Sort, "-")
+		l.page = 0
+		l.cursor = cursor
+		l.rawCursor = s
+	}
+	return l, nil
+}
+
+// parsePage 解析 page 和 page_size 参数，page 默认为 1
+func parsePage(c *gin.Context, defaultSize int) (page, pageSize int, err error) {
+	page, pageSize = 1, defaultSize
+
+	if s := c.Query("page_size"); s != "" {
+		pageSize, err = strconv.Atoi(s)
+		if err != nil || pageSize < 1 || pageSize > maxPageSize {
+			return 0, 0, fmt.Errorf("page_size must be between 1 and %d", maxPageSize)
+		}
+	}
+	if s := c.Query("page"); s != "" {
+		page, err = strconv.Atoi(s)
+		if err != nil || page < 1 {
+			return 0, 0, errors.New("page must be a positive integer")
+		}
+	}
+	return page, pageSize, nil
+}
+
+// find 按分页和排序参数查询比赛，query 只需包含过滤条件
+func (l *contestList) find(query *gorm.DB) ([]model.Contest, *response.PageMeta, error) {
+	query = query.Model(&model.Contest{}).Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	dir, op := "ASC", ">"
+	if l.desc {
+		dir, op = "DESC", "<"
+	}
+	// 追加 id 排序使顺序稳定，游标才能准确定位
+	page := query.Order(l.field.column + " " + dir).Order("id " + dir)
+
+	if l.cursor != nil {
+		value, err := l.field.decode(l.cursor.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		page = page.Where(
+			fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?))", l.field.column, op),
+			value, value, l.cursor.ID,
+		)
+	} else {
+		page = page.Offset((l.page - 1) * l.pageSize)
+	}
+
+	// 多取一条用于判断是否还有下一页
+	var contests []model.Contest
+	if err := page.Limit(l.pageSize + 1).Find(&contests).Error; err != nil {
+		return nil, nil, err
+	}
+
+	meta := &response.PageMeta{
+		Total:    total,
+		Page:     l.page,
+		PageSize: l.pageSize,
+		Sort:     l.sort,
+	}
+	if len(contests) > l.pageSize {
+		contests = contests[:l.pageSize]
+		meta.HasMore = true
+		cursor, err := l.encodeCursor(&contests[len(contests)-1])
+		if err != nil {
+			return nil, nil, err
+		}
+		meta.NextCursor = cursor
+	}
+	return contests, meta, nil
+}
+
+// key 分页和排序参数的规范化表示，用作缓存键的一部分
+func (l *contestList) key() string {
+	return fmt.Sprintf("sort=%s&page=%d&page_size=%d&cursor=%s", l.sort, l.page, l.pageSize, l.rawCursor)
+}
+
+func (l *contestList) encodeCursor(last *model.Contest) (string, error) {
+	value, err := json.Marshal(l.field.value(last))
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(contestCursor{Sort: l.sort, Value: value, ID: last.ID})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeContestCursor(s string) (*contestCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var cursor contestCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	if _, ok := contestSortFields[strings.TrimPrefix(cursor.Sort, "-")]; !ok {
+		return nil, fmt.Errorf("unsupported sort: %s", cursor.Sort)
+	}
+	return &cursor, nil
+}
+
+// contestPage 缓存的一页比赛查询结果
+// 缓存查询结果而不是响应，倒计时、时区和语言在每次请求时重新计算
+type contestPage struct {
+	Contests []model.Contest    `json:"contests"`
+	Meta     *response.PageMeta `json:"meta"`
+}
+
+// listContests 按请求中的分页和排序参数查询比赛并返回带分页信息的响应，比赛时间输出为 loc 时区，按请求语言本地化
+// 查询结果按 scope 缓存，客户端缓存仍有效时返回 304
+func listContests(c *gin.Context, query *gorm.DB, defaultSort string, loc *time.Location, scope cacheScope) {
+	list, err := parseContestList(c, defaultSort)
+	if err != nil {
+		response.Fail(c, response.ErrInvalidRequest.WithTips(err.Error()))
+		return
+	}
+
+	page, digest, err := cache.Load(c.Request.Context(), scope.key+"&"+list.key(), scope.tags, func() (contestPage, error) {
+		contests, meta, err := list.find(query)
+		return contestPage{Contests: contests, Meta: meta}, err
+	})
+	if err != nil {
+		response.Fail(c, response.ErrServerInternal)
+		return
+	}
+
+	lang := i18n.FromRequest(c)
+	if notModified(c, digest, loc, lang) {
+		return
+	}
+
+	contestDtos := make([]model.ContestDto, 0, len(page.Contests))
+	for _, contest := range page.Contests {
+		contestDtos = append(contestDtos, toContestDto(&contest, loc, lang))
+	}
+
+	response.SuccessWithMeta(c, contestDtos, page.Meta)
+}
@@ -0,0 +1,183 @@
+package crawler
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"nicccce-acm-calendar-api/internal/global/search"
+	"nicccce-acm-calendar-api/internal/model"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB 创建只包含比赛表的内存数据库
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&model.Contest{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func createTestContest(t *testing.T, db *gorm.DB, name string, start time.Time) *model.Contest {
+	t.Helper()
+	var count int64
+	db.Model(&model.Contest{}).Count(&count)
+	contest := &model.Contest{
+		Name:        name,
+		Platform:    "atcoder",
+		StartTime:   start,
+		EndTime:     start.Add(time.Hour),
+		ContestURL:  "https://atcoder.jp",
+		SourceID:    fmt.Sprintf("atcoder-%d", count),
+		LastUpdated: start,
+		SearchText:  search.Text(name),
+	}
+	if err := db.Create(contest).Error; err != nil {
+		t.Fatal(err)
+	}
+	return contest
+}
+
+func TestContestCursorRoundTrip(t *testing.T) {
+	contest := &model.Contest{
+		Model:           model.Model{ID: 42},
+		Platform:        "atcoder",
+		StartTime:       time.Date(2024, 11, 20, 12, 0, 0, 0, time.UTC),
+		EndTime:         time.Date(2024, 11, 20, 13, 40, 0, 0, time.UTC),
+		DurationSeconds: 6000,
+	}
+
+	tests := []struct {
+		sort string
+		want any
+	}{
+		{"start_time", contest.StartTime},
+		{"-start_time", contest.StartTime},
+		{"end_time", contest.EndTime},
+		{"duration", contest.DurationSeconds},
+		{"-platform", contest.Platform},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			field := contestSortFields[strings.TrimPrefix(tt.sort, "-")]
+			l := &contestList{sort: tt.sort, field: field}
+
+			s, err := l.encodeCursor(contest)
+			if err != nil {
+				t.Fatalf("encodeCursor: %v", err)
+			}
+			cursor, err := decodeContestCursor(s)
+			if err != nil {
+				t.Fatalf("decodeContestCursor(%q): %v", s, err)
+			}
+			if cursor.Sort != tt.sort || cursor.ID != contest.ID {
+				t.Fatalf("cursor = {%s %d}, want {%s %d}", cursor.Sort, cursor.ID, tt.sort, contest.ID)
+			}
+			value, err := field.decode(cursor.Value)
+			if err != nil {
+				t.Fatalf("decode value: %v", err)
+			}
+			if tm, ok := tt.want.(time.Time); ok {
+				if !value.(time.Time).Equal(tm) {
+					t.Fatalf("value = %v, want %v", value, tm)
+				}
+				return
+			}
+			if value != tt.want {
+				t.Fatalf("value = %v, want %v", value, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeContestCursorInvalid(t *testing.T) {
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "!!!"},
+		{"not json", encode("start_time")},
+		{"unsupported sort", encode(`{"s":"name","v":"a","id":1}`)},
+		{"empty sort", encode(`{"v":"a","id":1}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeContestCursor(tt.cursor); err == nil {
+				t.Fatalf("decodeContestCursor(%q) succeeded, want error", tt.cursor)
+			}
+		})
+	}
+}
+
+// TestContestListCursorTieBreak 排序值相同的比赛按 id 排序，游标翻页时既不重复也不遗漏
+func TestContestListCursorTieBreak(t *testing.T) {
+	db := newTestDB(t)
+
+	base := time.Date(2024, 11, 20, 12, 0, 0, 0, time.UTC)
+	// 前 5 场开始时间相同，且跨越多页
+	starts := []time.Time{base, base, base, base, base, base.Add(-time.Hour), base.Add(time.Hour)}
+	for i, start := range starts {
+		createTestContest(t, db, fmt.Sprintf("contest %d", i), start)
+	}
+
+	for _, sort := range []string{"start_time", "-start_time"} {
+		t.Run(sort, func(t *testing.T) {
+			l := &contestList{sort: sort, field: contestSortFields["start_time"], desc: strings.HasPrefix(sort, "-"), page: 1, pageSize: 2}
+
+			var got []model.Contest
+			for pages := 0; ; pages++ {
+				if pages > len(starts) {
+					t.Fatal("cursor pagination did not terminate")
+				}
+				contests, meta, err := l.find(db)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if meta.Total != int64(len(starts)) {
+					t.Fatalf("total = %d, want %d", meta.Total, len(starts))
+				}
+				got = append(got, contests...)
+				if !meta.HasMore {
+					break
+				}
+				if l.cursor, err = decodeContestCursor(meta.NextCursor); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if len(got) != len(starts) {
+				t.Fatalf("got %d contests, want %d", len(got), len(starts))
+			}
+			seen := make(map[uint]bool)
+			for i, c := range got {
+				if seen[c.ID] {
+					t.Fatalf("contest %d returned twice", c.ID)
+				}
+				seen[c.ID] = true
+				if i == 0 {
+					continue
+				}
+				prev := got[i-1]
+				ordered := prev.StartTime.Before(c.StartTime) || (prev.StartTime.Equal(c.StartTime) && prev.ID < c.ID)
+				if l.desc {
+					ordered = prev.StartTime.After(c.StartTime) || (prev.StartTime.Equal(c.StartTime) && prev.ID > c.ID)
+				}
+				if !ordered {
+					t.Fatalf("contest %d (%s) is out of order after %d (%s)", c.ID, c.StartTime, prev.ID, prev.StartTime)
+				}
+			}
+		})
+	}
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"nicccce-acm-calendar-api/internal/global/httpclient"
+	"nicccce-acm-calendar-api/internal/global/platform"
 	"nicccce-acm-calendar-api/internal/model"
 	"time"
 
 	"github.com/go-resty/resty/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type LuoguCrawler struct {
@@ -15,12 +19,12 @@ type LuoguCrawler struct {
 }
 
 func (c *LuoguCrawler) Name() string {
-	return "Luogu"
+	return platform.Luogu
 }
 
 func (c *LuoguCrawler) Crawl(ctx context.Context) ([]*model.Contest, error) {
 	if c.client == nil {
-		c.client = resty.New()
+		c.client = httpclient.New()
 	}
 
 	// 洛谷API接口（需要设置User-Agent）
@@ -39,6 +43,9 @@ func (c *LuoguCrawler) Crawl(ctx context.Context) ([]*model.Contest, error) {
 		return nil, fmt.Errorf("Luogu returned status code: %d", resp.StatusCode())
 	}
 
+	_, span := tracer.Start(ctx, "crawler.parse", trace.WithAttributes(attribute.String("platform", c.Name())))
+	defer span.End()
+
 	var response struct {
 		CurrentData struct {
 			Contests struct {
@@ -64,6 +71,11 @@ func (c *LuoguCrawler) Crawl(ctx context.Context) ([]*model.Contest, error) {
 		startTime := time.Unix(contestData.StartTime, 0)
 		endTime := time.Unix(contestData.EndTime, 0)
 		duration := contestData.EndTime - contestData.StartTime
+		if err := validateContest(contestData.Name, startTime, endTime); err != nil {
+			recordParseError(ctx, c.Name(), fmt.Errorf("invalid Luogu contest %d: %w", contestData.ID, err))
+			continue
+		}
+		recordParsed(ctx)
 
 		// 跳过已经结束很久的比赛
 		if endTime.Before(now.AddDate(0, -3, 0)) {
@@ -80,7 +92,7 @@ func (c *LuoguCrawler) Crawl(ctx context.Context) ([]*model.Contest, error) {
 
 		contests = append(contests, &model.Contest{
 			Name:            contestData.Name,
-			Platform:        "洛谷",
+			Platform:        c.Name(),
 			StartTime:       startTime,
 			EndTime:         endTime,
 			DurationSeconds: duration,
@@ -91,6 +103,7 @@ func (c *LuoguCrawler) Crawl(ctx context.Context) ([]*model.Contest, error) {
 		})
 	}
 
+	span.SetAttributes(attribute.Int("contests", len(contests)))
 	return contests, nil
 }
 
@@ -108,12 +121,12 @@ func (c *LuoguCrawler) crawlMultiplePages(ctx context.Context) ([]*model.Contest
 			Get(url)
 
 		if err != nil {
-			fmt.Printf("Failed to fetch Luogu page %d: %v\n", page, err)
+			log.WarnContext(ctx, "Failed to fetch Luogu page", "page", page, "error", err)
 			continue
 		}
 
 		if resp.StatusCode() != 200 {
-			fmt.Printf("Luogu page %d returned status code: %d\n", page, resp.StatusCode())
+			log.WarnContext(ctx, "Unexpected Luogu page status", "page", page, "status_code", resp.StatusCode())
 			continue
 		}
 
@@ -131,7 +144,7 @@ func (c *LuoguCrawler) crawlMultiplePages(ctx context.Context) ([]*model.Contest
 		}
 
 		if err := json.Unmarshal(resp.Body(), &response); err != nil {
-			fmt.Printf("Failed to parse Luogu page %d: %v\n", page, err)
+			log.WarnContext(ctx, "Failed to parse Luogu page", "page", page, "error", err)
 			continue
 		}
 
@@ -155,7 +168,7 @@ func (c *LuoguCrawler) crawlMultiplePages(ctx context.Context) ([]*model.Contest
 
 			allContests = append(allContests, &model.Contest{
 				Name:            contestData.Name,
-				Platform:        "洛谷",
+				Platform:        c.Name(),
 				StartTime:       startTime,
 				EndTime:         endTime,
 				DurationSeconds: duration,
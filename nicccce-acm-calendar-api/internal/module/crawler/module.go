@@ -1,15 +1,34 @@
 package crawler
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"nicccce-acm-calendar-api/internal/global/apikey"
+	"nicccce-acm-calendar-api/internal/global/health"
+	"nicccce-acm-calendar-api/internal/global/logger"
+	"nicccce-acm-calendar-api/internal/global/middleware"
+	"nicccce-acm-calendar-api/internal/global/tracing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+var (
+	log    *slog.Logger
+	tracer = tracing.Tracer("crawler")
+)
+
+// adminRoleID 用户 Token 的角色 ID 不小于该值时可访问管理接口
+const adminRoleID = 1
+
 type ModuleCrawler struct {
 	service   *CrawlerService
 	scheduler *Scheduler
-	limiter   *RateLimiter
+	events    *EventBroker
+	jobs      *RefreshJobQueue
+	status    *StatusTracker
 }
 
 func (m *ModuleCrawler) GetName() string {
@@ -17,52 +36,140 @@ func (m *ModuleCrawler) GetName() string {
 }
 
 func (m *ModuleCrawler) Init() {
+	log = logger.New("Crawler")
+
 	// 初始化爬虫
 	InitCrawlers()
-	
+	if err := seedPlatforms(context.Background()); err != nil {
+		log.Error("Failed to seed platforms", "error", err)
+	}
+
 	// 创建服务实例
-	m.service = NewCrawlerService()
+	m.events = NewEventBroker()
+	m.service = NewCrawlerService(m.events)
 	m.scheduler = NewScheduler(m.service)
-	m.limiter = NewRateLimiter()
+	m.jobs = NewRefreshJobQueue(m.service)
+	m.status = NewStatusTracker(m.service, m.scheduler, m.events)
+
+	// 调度器和爬虫异常不影响比赛查询，注册为非关键组件
+	health.Register("scheduler", false, m.checkScheduler)
+	health.Register("crawlers", false, m.checkCrawlers)
+}
+
+// checkScheduler 报告调度器运行状态、主节点身份、各定时任务的下次执行时间和下一次比赛状态变化的时间
+func (m *ModuleCrawler) checkScheduler(ctx context.Context) (any, error) {
+	details := map[string]any{
+		"running":                m.scheduler.Running(),
+		"leader":                 m.scheduler.IsLeader(),
+		"jobs":                   m.scheduler.GetScheduledJobs(),
+		"next_status_transition": m.status.Next(),
+	}
+	if !m.scheduler.Running() {
+		return details, errors.New("scheduler is not running")
+	}
+	return details, nil
+}
+
+// checkCrawlers 报告各平台上次成功爬取的时间，有平台处于异常状态时报告降级
+func (m *ModuleCrawler) checkCrawlers(ctx context.Context) (any, error) {
+	snapshot, err := m.service.Health().Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	type platformStatus struct {
+		Status        HealthStatus `json:"status"`
+		LastSuccessAt *time.Time   `json:"last_success_at,omitempty"`
+	}
+	details := make(map[string]platformStatus, len(snapshot))
+	var unhealthy []string
+	for _, h := range snapshot {
+		details[h.Platform] = platformStatus{Status: h.Status, LastSuccessAt: h.LastSuccessAt}
+		if h.Status == HealthDegraded || h.Status == HealthFailing {
+			unhealthy = append(unhealthy, h.Platform)
+		}
+	}
+	if len(unhealthy) > 0 {
+		return details, &health.DegradedError{Reason: fmt.Sprintf("unhealthy platforms: %v", unhealthy)}
+	}
+	return details, nil
+}
+
+func (m *ModuleCrawler) Start() {
+	// 启动事件广播
+	m.events.Start()
+
+	// 启动异步刷新任务的工作协程
+	m.jobs.Start()
 
 	// 启动定时任务
 	if err := m.scheduler.Start(); err != nil {
 		panic("Failed to start scheduler: " + err.Error())
 	}
 
-	// 初始化时立即更新比赛状态
-	if err := UpdateContestStatus(); err != nil {
-		panic("Failed to update contest status: " + err.Error())
-	}
+	// 在比赛开始和结束时更新比赛状态，启动时会立即同步一次
+	m.status.Start()
+}
+
+// Stop 依次关闭事件广播、比赛状态同步、定时任务和刷新任务队列
+// 先关闭事件广播以结束 SSE 长连接，HTTP 服务才能在超时前完成排空
+func (m *ModuleCrawler) Stop(ctx context.Context) {
+	m.events.Close()
+	m.status.Stop(ctx)
+	m.scheduler.Stop(ctx)
+	m.jobs.Stop(ctx)
+	log.Info("Crawler stopped")
 }
 
 func (m *ModuleCrawler) InitRouter(r *gin.RouterGroup) {
 	// 比赛相关API
+	// 携带 API Key 的请求需具有对应的授权范围，匿名请求不受影响
+	readScope := middleware.Scope(apikey.ScopeContestsRead)
 	contestGroup := r.Group("/contests")
+	contestGroup.Use(readScope)
 	{
 		contestGroup.GET("", m.GetContests)
+		contestGroup.GET("/search", m.SearchContests)
+		contestGroup.GET("/changes", m.GetContestChanges)
 		contestGroup.GET("/:id", m.GetContestByID)
 		contestGroup.GET("/platform/:platform", m.GetContestsByPlatform)
 		contestGroup.GET("/status/:status", m.GetContestsByStatus)
 	}
 
-	// 刷新相关API（需要速率限制）
+	// 平台列表
+	r.GET("/platforms", readScope, m.GetPlatforms)
+
+	// 事件推送API（SSE）
+	r.GET("/events", readScope, m.StreamEvents)
+
+	// 刷新相关API（需要速率限制，见 ratelimit 的 refresh 策略）
+	// 匿名请求可以刷新，携带 API Key 时需具有 refresh 授权范围
 	refreshGroup := r.Group("/refresh")
-	refreshGroup.Use(m.limiter.GinMiddleware(5, time.Minute)) // 每分钟5次
+	refreshGroup.Use(middleware.Scope(apikey.ScopeRefresh))
 	{
 		refreshGroup.POST("", m.RefreshAllPlatforms)
 		refreshGroup.POST("/:platform", m.RefreshSinglePlatform)
 		refreshGroup.GET("/status", m.GetRefreshStatus)
+		refreshGroup.GET("/jobs/:id", m.GetRefreshJob)
 		refreshGroup.GET("/limit", m.GetRateLimitInfo)
 	}
 
-	// 管理API
+	// 管理API，需要管理员 Token 或具有 admin 授权范围的 API Key
+	adminAuth := middleware.AuthOrAPIKey(adminRoleID, apikey.ScopeAdmin)
 	adminGroup := r.Group("/admin/contests")
+	adminGroup.Use(adminAuth)
 	{
 		adminGroup.GET("/stats", m.GetContestStats)
 		adminGroup.GET("/logs", m.GetRefreshLogs)
 		adminGroup.DELETE("/:id", m.DeleteContest)
 	}
+
+	// 爬虫监控API
+	crawlerAdminGroup := r.Group("/admin/crawlers")
+	crawlerAdminGroup.Use(adminAuth)
+	{
+		crawlerAdminGroup.GET("/health", m.GetCrawlerHealth)
+	}
 }
 
 // GetService 获取爬虫服务实例
@@ -75,7 +182,12 @@ func (m *ModuleCrawler) GetScheduler() *Scheduler {
 	return m.scheduler
 }
 
-// GetLimiter 获取限流器实例
-func (m *ModuleCrawler) GetLimiter() *RateLimiter {
-	return m.limiter
-}
\ No newline at end of file
+// GetJobs 获取刷新任务队列实例
+func (m *ModuleCrawler) GetJobs() *RefreshJobQueue {
+	return m.jobs
+}
+
+// GetEvents 获取事件广播实例
+func (m *ModuleCrawler) GetEvents() *EventBroker {
+	return m.events
+}
@@ -5,12 +5,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"html"
+	"nicccce-acm-calendar-api/internal/global/httpclient"
+	"nicccce-acm-calendar-api/internal/global/platform"
 	"nicccce-acm-calendar-api/internal/model"
 	"strings"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/go-resty/resty/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type NowCoderCrawler struct {
@@ -18,12 +22,12 @@ type NowCoderCrawler struct {
 }
 
 func (c *NowCoderCrawler) Name() string {
-	return "NowCoder"
+	return platform.NowCoder
 }
 
 func (c *NowCoderCrawler) Crawl(ctx context.Context) ([]*model.Contest, error) {
 	if c.client == nil {
-		c.client = resty.New()
+		c.client = httpclient.New()
 	}
 
 	// 牛客竞赛页面（学校竞赛）
@@ -41,6 +45,9 @@ func (c *NowCoderCrawler) Crawl(ctx context.Context) ([]*model.Contest, error) {
 		return nil, fmt.Errorf("NowCoder returned status code: %d", resp.StatusCode())
 	}
 
+	_, span := tracer.Start(ctx, "crawler.parse", trace.WithAttributes(attribute.String("platform", c.Name())))
+	defer span.End()
+
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(resp.String()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
@@ -53,14 +60,16 @@ func (c *NowCoderCrawler) Crawl(ctx context.Context) ([]*model.Contest, error) {
 	doc.Find(".platform-item.js-item").Each(func(i int, s *goquery.Selection) {
 		contest, err := c.parseContestItem(s, now)
 		if err != nil {
-			fmt.Printf("Failed to parse NowCoder contest item: %v\n", err)
+			recordParseError(ctx, c.Name(), fmt.Errorf("failed to parse NowCoder contest item: %w", err))
 			return
 		}
+		recordParsed(ctx)
 		if contest != nil {
 			contests = append(contests, contest)
 		}
 	})
 
+	span.SetAttributes(attribute.Int("contests", len(contests)))
 	return contests, nil
 }
 
@@ -116,7 +125,7 @@ func (c *NowCoderCrawler) parseContestItem(s *goquery.Selection, now time.Time)
 
 	return &model.Contest{
 		Name:            contestData.ContestName,
-		Platform:        "牛客",
+		Platform:        c.Name(),
 		StartTime:       startTime,
 		EndTime:         endTime,
 		DurationSeconds: duration,
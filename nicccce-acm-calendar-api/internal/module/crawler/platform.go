@@ -0,0 +1,76 @@
+package crawler
+
+import (
+	"context"
+
+	"nicccce-acm-calendar-api/internal/global/database"
+	"nicccce-acm-calendar-api/internal/global/i18n"
+	"nicccce-acm-calendar-api/internal/global/platform"
+	"nicccce-acm-calendar-api/internal/global/response"
+	"nicccce-acm-calendar-api/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+)
+
+// seedPlatforms 将平台注册表写入平台表，已有平台只更新注册表维护的字段，保留是否激活等运行时配置
+func seedPlatforms(ctx context.Context) error {
+	rows := make([]model.ContestPlatform, 0, len(platform.All()))
+	for i, p := range platform.All() {
+		rows = append(rows, model.ContestPlatform{
+			Name:          p.Slug,
+			DisplayName:   p.Name(i18n.ZH),
+			DisplayNameEn: p.Name(i18n.EN),
+			Homepage:      p.Homepage,
+			Icon:          p.Icon,
+			Color:         p.Color,
+			SortOrder:     i,
+			IsActive:      true,
+		})
+	}
+
+	return database.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"display_name", "display_name_en", "homepage", "icon", "color", "sort_order", "updated_at",
+		}),
+	}).Create(&rows).Error
+}
+
+// GetPlatforms 获取支持的平台列表，显示名称按请求语言本地化
+func (m *ModuleCrawler) GetPlatforms(c *gin.Context) {
+	var rows []model.ContestPlatform
+	if err := database.DB.Find(&rows).Error; err != nil {
+		response.Fail(c, response.ErrServerInternal)
+		return
+	}
+	active := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		active[row.Name] = row.IsActive
+	}
+
+	lang := i18n.FromRequest(c)
+	platforms := make([]model.PlatformDto, 0, len(platform.All()))
+	for _, p := range platform.All() {
+		names := make(map[string]string, len(p.Names))
+		for l, name := range p.Names {
+			names[string(l)] = name
+		}
+		isActive, ok := active[p.Slug]
+		if !ok {
+			// 平台表写入失败时按注册表视为激活
+			isActive = true
+		}
+		platforms = append(platforms, model.PlatformDto{
+			Slug:     p.Slug,
+			Name:     p.Name(lang),
+			Names:    names,
+			Homepage: p.Homepage,
+			Icon:     p.Icon,
+			Color:    p.Color,
+			IsActive: isActive,
+		})
+	}
+
+	response.Success(c, platforms)
+}
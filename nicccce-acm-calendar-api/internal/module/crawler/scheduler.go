@@ -3,7 +3,11 @@ package crawler
 import (
 	"context"
 	"fmt"
+	"nicccce-acm-calendar-api/internal/global/logger"
+	"nicccce-acm-calendar-api/internal/global/metrics"
+	"nicccce-acm-calendar-api/tools"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/robfig/cron/v3"
@@ -12,15 +16,27 @@ import (
 type Scheduler struct {
 	cron           *cron.Cron
 	crawlerService *CrawlerService
+	elector        *LeaderElector
 	mu             sync.RWMutex
 	jobs           map[string]cron.EntryID
+	running        atomic.Bool
+	// specs 记录每个定时任务的名称和表达式（cron.EntryID -> jobSpec），供状态查询使用
+	specs sync.Map
+
+	// ctx 为所有定时任务的父 context，停机超时后取消以中断仍在执行的任务
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 func NewScheduler(crawlerService *CrawlerService) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Scheduler{
 		cron:           cron.New(cron.WithSeconds()),
 		crawlerService: crawlerService,
+		elector:        NewLeaderElector(schedulerLeaderKey),
 		jobs:           make(map[string]cron.EntryID),
+		ctx:            ctx,
+		cancel:         cancel,
 	}
 }
 
@@ -31,27 +47,81 @@ func (s *Scheduler) Start() error {
 
 	// 添加默认的定时任务
 	// 每天凌晨2点刷新所有平台
-	if _, err := s.cron.AddFunc("0 0 2 * * *", s.refreshAllPlatformsJob); err != nil {
+	if _, err := s.addJob("refresh_all", "0 0 2 * * *", s.refreshAllPlatformsJob); err != nil {
 		return fmt.Errorf("failed to add daily refresh job: %w", err)
 	}
 
-	// 每小时更新比赛状态
-	if _, err := s.cron.AddFunc("0 0 * * * *", s.updateContestStatusJob); err != nil {
-		return fmt.Errorf("failed to add status update job: %w", err)
+	// 每10分钟检查爬虫健康状态（如长时间未成功刷新）
+	if _, err := s.addJob("health_check", "0 */10 * * * *", s.healthCheckJob); err != nil {
+		return fmt.Errorf("failed to add health check job: %w", err)
 	}
 
+	// 多实例部署时只有主节点执行定时任务
+	s.elector.Start()
 	s.cron.Start()
+	s.running.Store(true)
 	return nil
 }
 
-// Stop 停止定时任务调度器
-func (s *Scheduler) Stop() {
+// Stop 停止定时任务调度器，等待正在执行的任务结束
+// ctx 到期时取消仍在执行的任务，并等待其退出
+func (s *Scheduler) Stop(ctx context.Context) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	s.running.Store(false)
 	if s.cron != nil {
-		s.cron.Stop()
+		done := s.cron.Stop()
+		select {
+		case <-done.Done():
+		case <-ctx.Done():
+			log.Warn("Scheduled jobs did not finish in time, cancelling")
+			s.cancel()
+			<-done.Done()
+		}
 	}
+	s.cancel()
+	s.elector.Stop()
+}
+
+// Running 调度器是否在运行
+func (s *Scheduler) Running() bool {
+	return s.running.Load()
+}
+
+// IsLeader 当前实例是否负责执行定时任务
+func (s *Scheduler) IsLeader() bool {
+	return s.elector.IsLeader()
+}
+
+// addJob 添加定时任务：非主节点跳过执行，并记录执行次数、耗时和调度延迟
+// 每次执行生成独立的请求ID，任务内的日志和刷新记录可据此串联
+func (s *Scheduler) addJob(name, spec string, job func(ctx context.Context)) (cron.EntryID, error) {
+	var id cron.EntryID
+	id, err := s.cron.AddFunc(spec, func() {
+		if !s.elector.IsLeader() {
+			metrics.SchedulerJobRuns.WithLabelValues(name, "skipped").Inc()
+			log.Debug("Skipping scheduled job on follower", "job", name)
+			return
+		}
+
+		// cron 在触发任务前会把 Prev 设置为本次的计划执行时间
+		if scheduled := s.cron.Entry(id).Prev; !scheduled.IsZero() {
+			metrics.SchedulerJobLag.WithLabelValues(name).Set(time.Since(scheduled).Seconds())
+		}
+
+		ctx := logger.WithRequestID(s.ctx, "cron-"+name+"-"+tools.RandString(8))
+		log.InfoContext(ctx, "Running scheduled job", "job", name)
+
+		startTime := time.Now()
+		job(ctx)
+		metrics.SchedulerJobRuns.WithLabelValues(name, "run").Inc()
+		metrics.SchedulerJobDuration.WithLabelValues(name).Observe(time.Since(startTime).Seconds())
+	})
+	if err == nil {
+		s.specs.Store(id, jobSpec{name: name, spec: spec})
+	}
+	return id, err
 }
 
 // AddPlatformRefreshJob 添加特定平台的定时刷新任务
@@ -64,12 +134,11 @@ func (s *Scheduler) AddPlatformRefreshJob(platform, schedule string) error {
 		return fmt.Errorf("crawler for platform %s not found", platform)
 	}
 
-	job := func() {
-		ctx := context.Background()
+	job := func(ctx context.Context) {
 		s.crawlerService.RefreshSinglePlatform(ctx, platform)
 	}
 
-	entryID, err := s.cron.AddFunc(schedule, job)
+	entryID, err := s.addJob("refresh_"+platform, schedule, job)
 	if err != nil {
 		return fmt.Errorf("failed to add refresh job for %s: %w", platform, err)
 	}
@@ -89,15 +158,14 @@ func (s *Scheduler) RemovePlatformRefreshJob(platform string) error {
 	}
 
 	s.cron.Remove(entryID)
+	s.specs.Delete(entryID)
 	delete(s.jobs, platform)
 	return nil
 }
 
 // GetScheduledJobs 获取所有定时任务信息
+// cron.Entries 本身是并发安全的，这里不加锁，以免停机等待任务结束时阻塞健康检查
 func (s *Scheduler) GetScheduledJobs() []JobInfo {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
 	var jobs []JobInfo
 	entries := s.cron.Entries()
 
@@ -108,6 +176,10 @@ func (s *Scheduler) GetScheduledJobs() []JobInfo {
 			Next:     entry.Next,
 			Prev:     entry.Prev,
 		}
+		if spec, ok := s.specs.Load(entry.ID); ok {
+			jobInfo.Name = spec.(jobSpec).name
+			jobInfo.Schedule = spec.(jobSpec).spec
+		}
 		jobs = append(jobs, jobInfo)
 	}
 
@@ -115,26 +187,21 @@ func (s *Scheduler) GetScheduledJobs() []JobInfo {
 }
 
 // refreshAllPlatformsJob 刷新所有平台的定时任务
-func (s *Scheduler) refreshAllPlatformsJob() {
-	ctx := context.Background()
+func (s *Scheduler) refreshAllPlatformsJob(ctx context.Context) {
 	results, err := s.crawlerService.RefreshAllPlatforms(ctx)
 	if err != nil {
-		fmt.Printf("Failed to refresh all platforms: %v\n", err)
+		log.ErrorContext(ctx, "Failed to refresh all platforms", "error", err)
 		return
 	}
 
 	for platform, result := range results {
-		fmt.Printf("Platform %s refresh result: %s\n", platform, result.Message)
+		log.InfoContext(ctx, "Scheduled refresh finished", "platform", platform, "status", result.Status, "message", result.Message)
 	}
 }
 
-// updateContestStatusJob 更新比赛状态的定时任务
-func (s *Scheduler) updateContestStatusJob() {
-	if err := UpdateContestStatus(); err != nil {
-		fmt.Printf("Failed to update contest status: %v\n", err)
-		return
-	}
-	fmt.Println("Contest status updated successfully")
+// healthCheckJob 检查爬虫健康状态的定时任务
+func (s *Scheduler) healthCheckJob(ctx context.Context) {
+	s.crawlerService.Health().Check(ctx)
 }
 
 // ManualRefresh 手动触发刷新
@@ -157,8 +224,14 @@ func (s *Scheduler) ManualRefresh(platform string) (*RefreshResult, error) {
 }
 
 type JobInfo struct {
-	ID       int
-	Schedule string
-	Next     time.Time
-	Prev     time.Time
+	ID       int       `json:"id"`
+	Name     string    `json:"name"`
+	Schedule string    `json:"schedule"`
+	Next     time.Time `json:"next"`
+	Prev     time.Time `json:"prev"`
+}
+
+type jobSpec struct {
+	name string
+	spec string
 }
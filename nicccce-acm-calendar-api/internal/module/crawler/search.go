@@ -0,0 +1,200 @@
+package crawler
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"nicccce-acm-calendar-api/internal/global/database"
+	"nicccce-acm-calendar-api/internal/global/i18n"
+	"nicccce-acm-calendar-api/internal/global/platform"
+	"nicccce-acm-calendar-api/internal/global/response"
+	"nicccce-acm-calendar-api/internal/global/search"
+	"nicccce-acm-calendar-api/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// searchMaxTokens 搜索词数量上限，超出部分忽略
+	searchMaxTokens = 8
+	// searchFuzzyPrefix 较长的搜索词只用前几个字符预筛选，使拼写错误的词也能进入候选
+	searchFuzzyPrefix = 2
+
+	defaultSearchPageSize = 20
+
+	// recencyWeight 时间加分的上限，比赛时间离现在越近加分越多，用于相关度相近时优先展示近期比赛
+	recencyWeight = 2.0
+	// recencyScale 时间加分衰减到一半所需的时间
+	recencyScale = 30 * 24 * time.Hour
+)
+
+// ContestSearchResult 搜索结果
+type ContestSearchResult struct {
+	model.ContestDto
+	Score float64 `json:"score"`
+	// Highlight HTML 转义后的比赛名称，匹配部分用 <em> 标记
+	Highlight string `json:"highlight"`
+}
+
+// searchCandidate 预筛选出的候选比赛，只包含计算相关度所需的列
+type searchCandidate struct {
+	ID        uint
+	Name      string
+	StartTime time.Time
+}
+
+// scoredCandidate 计算过相关度的候选比赛
+type scoredCandidate struct {
+	searchCandidate
+	score float64
+	match search.Result
+}
+
+// SearchContests 按名称搜索比赛，不限时间范围
+// 先用搜索文本预筛选候选比赛，对全部候选按相关度和时间远近排序，再只查询当前页的完整数据
+func (m *ModuleCrawler) SearchContests(c *gin.Context) {
+	tokens := search.Tokenize(c.Query("q"))
+	if len(tokens) == 0 {
+		response.Fail(c, response.ErrInvalidRequest.WithTips("q is required"))
+		return
+	}
+	if len(tokens) > searchMaxTokens {
+		tokens = tokens[:searchMaxTokens]
+	}
+
+	page, pageSize, err := parsePage(c, defaultSearchPageSize)
+	if err != nil {
+		response.Fail(c, response.ErrInvalidRequest.WithTips(err.Error()))
+		return
+	}
+	loc, startFrom, startTo, ok := parseTimeParams(c)
+	if !ok {
+		return
+	}
+
+	query := database.DB.Model(&model.Contest{})
+	if name := c.Query("platform"); name != "" {
+		query = query.Where("platform = ?", platform.Normalize(name))
+	}
+	if status := c.Query("status"); status != "" {
+		query = whereStatus(query, status, time.Now())
+	}
+	query = whereStartTime(query, startFrom, startTo)
+
+	candidates, err := searchCandidates(query, tokens)
+	if err != nil {
+		response.Fail(c, response.ErrServerInternal)
+		return
+	}
+
+	now := time.Now()
+	scored := make([]scoredCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		match := search.Match(candidate.Name, tokens)
+		if match.Score == 0 {
+			continue
+		}
+		scored = append(scored, scoredCandidate{
+			searchCandidate: candidate,
+			score:           math.Round((match.Score+recencyBonus(candidate.StartTime, now))*100) / 100,
+			match:           match,
+		})
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		if scored[i].score != scored[j].score {
+			return scored[i].score > scored[j].score
+		}
+		return scored[i].StartTime.After(scored[j].StartTime)
+	})
+
+	meta := &response.PageMeta{
+		Total:    int64(len(scored)),
+		Page:     page,
+		PageSize: pageSize,
+		Sort:     "relevance",
+	}
+	from := min((page-1)*pageSize, len(scored))
+	to := min(from+pageSize, len(scored))
+	meta.HasMore = to < len(scored)
+	scored = scored[from:to]
+
+	ids := make([]uint, len(scored))
+	for i, s := range scored {
+		ids[i] = s.ID
+	}
+	var contests []model.Contest
+	if len(ids) > 0 {
+		if err := database.DB.Where("id IN ?", ids).Find(&contests).Error; err != nil {
+			response.Fail(c, response.ErrServerInternal)
+			return
+		}
+	}
+	byID := make(map[uint]*model.Contest, len(contests))
+	for i := range contests {
+		byID[contests[i].ID] = &contests[i]
+	}
+
+	lang := i18n.FromRequest(c)
+	results := make([]ContestSearchResult, 0, len(scored))
+	for _, s := range scored {
+		contest, ok := byID[s.ID]
+		if !ok {
+			// 预筛选后被删除的比赛
+			continue
+		}
+		results = append(results, ContestSearchResult{
+			ContestDto: toContestDto(contest, loc, lang),
+			Score:      s.score,
+			Highlight:  search.Highlight(contest.Name, s.match.Ranges),
+		})
+	}
+
+	response.SuccessWithMeta(c, results, meta)
+}
+
+// searchCandidates 预筛选包含全部搜索词的比赛，没有时退化为包含任一搜索词
+// 搜索文本包含名称、缩写和拼音，筛选全部在数据库中完成且不限数量，较早的比赛同样能被搜到
+func searchCandidates(query *gorm.DB, tokens []string) ([]searchCandidate, error) {
+	query = query.Select("id", "name", "start_time").Session(&gorm.Session{})
+
+	patterns := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if runes := []rune(token); len(runes) > searchFuzzyPrefix {
+			token = string(runes[:searchFuzzyPrefix])
+		}
+		patterns = append(patterns, "%"+token+"%")
+	}
+
+	var all []searchCandidate
+	matchAll := query
+	for _, p := range patterns {
+		matchAll = matchAll.Where("search_text LIKE ?", p)
+	}
+	if err := matchAll.Find(&all).Error; err != nil {
+		return nil, err
+	}
+	if len(all) > 0 || len(patterns) == 1 {
+		return all, nil
+	}
+
+	anyToken := query.Session(&gorm.Session{NewDB: true}).Where("search_text LIKE ?", patterns[0])
+	for _, p := range patterns[1:] {
+		anyToken = anyToken.Or("search_text LIKE ?", p)
+	}
+	var some []searchCandidate
+	if err := query.Where(anyToken).Find(&some).Error; err != nil {
+		return nil, err
+	}
+	return some, nil
+}
+
+// recencyBonus 比赛开始时间距现在越近加分越多，相差 recencyScale 时为上限的一半
+func recencyBonus(startTime, now time.Time) float64 {
+	distance := now.Sub(startTime)
+	if distance < 0 {
+		distance = -distance
+	}
+	return recencyWeight / (1 + float64(distance)/float64(recencyScale))
+}
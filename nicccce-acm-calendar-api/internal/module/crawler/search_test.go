@@ -0,0 +1,70 @@
+package crawler
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"nicccce-acm-calendar-api/internal/global/search"
+	"nicccce-acm-calendar-api/internal/model"
+)
+
+func TestSearchCandidates(t *testing.T) {
+	db := newTestDB(t)
+	now := time.Now()
+
+	abc100 := createTestContest(t, db, "AtCoder Beginner Contest 100", now.AddDate(-6, 0, 0))
+	abc380 := createTestContest(t, db, "AtCoder Beginner Contest 380", now)
+	arc190 := createTestContest(t, db, "AtCoder Regular Contest 190", now)
+	zhousai := createTestContest(t, db, "牛客周赛 Round 70", now)
+
+	tests := []struct {
+		name  string
+		query string
+		want  []uint
+	}{
+		// 多年前的比赛同样进入候选
+		{"old contests", "abc", []uint{abc100.ID, abc380.ID}},
+		{"all tokens", "abc 380", []uint{abc380.ID}},
+		// 没有比赛匹配全部搜索词时退化为匹配任一搜索词
+		{"any token", "arc 380", []uint{abc380.ID, arc190.ID}},
+		{"pinyin", "zhousai", []uint{zhousai.ID}},
+		// 较长的搜索词只用前缀预筛选，拼写错误也能进入候选
+		{"typo", "begniner", []uint{abc100.ID, abc380.ID}},
+		{"no match", "leetcode", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates, err := searchCandidates(db.Model(&model.Contest{}), search.Tokenize(tt.query))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []uint
+			for _, c := range candidates {
+				got = append(got, c.ID)
+			}
+			sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+			if len(got) != len(tt.want) {
+				t.Fatalf("searchCandidates(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("searchCandidates(%q) = %v, want %v", tt.query, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRecencyBonus(t *testing.T) {
+	now := time.Now()
+	if got := recencyBonus(now, now); got != recencyWeight {
+		t.Fatalf("recencyBonus(now) = %v, want %v", got, recencyWeight)
+	}
+	if got := recencyBonus(now.Add(-recencyScale), now); got != recencyWeight/2 {
+		t.Fatalf("recencyBonus(now-scale) = %v, want %v", got, recencyWeight/2)
+	}
+	if past, future := recencyBonus(now.Add(-recencyScale), now), recencyBonus(now.Add(recencyScale), now); past != future {
+		t.Fatalf("recencyBonus should be symmetric, got %v and %v", past, future)
+	}
+}
@@ -2,21 +2,51 @@ package crawler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"nicccce-acm-calendar-api/internal/global/database"
+	"nicccce-acm-calendar-api/internal/global/i18n"
+	"nicccce-acm-calendar-api/internal/global/logger"
+	"nicccce-acm-calendar-api/internal/global/metrics"
+	"nicccce-acm-calendar-api/internal/global/platform"
+	redisclient "nicccce-acm-calendar-api/internal/global/redis"
+	"nicccce-acm-calendar-api/internal/global/search"
+	"nicccce-acm-calendar-api/internal/global/timezone"
 	"nicccce-acm-calendar-api/internal/model"
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+const (
+	// upsertBatchSize 批量写入比赛时每条 SQL 的行数
+	upsertBatchSize = 100
+
+	refreshLockKeyPrefix = "refresh:lock:"
+	refreshLockTTL       = 30 * time.Second
+)
+
+// errRefreshLockLost 刷新过程中锁续期失败，其他实例可能已经开始刷新同一平台
+var errRefreshLockLost = errors.New("refresh lock lost")
+
 type CrawlerService struct {
-	mu sync.RWMutex
+	mu       sync.RWMutex
+	events   *EventBroker
+	executor *CrawlerExecutor
+	health   *HealthMonitor
 }
 
-func NewCrawlerService() *CrawlerService {
-	return &CrawlerService{}
+func NewCrawlerService(events *EventBroker) *CrawlerService {
+	return &CrawlerService{
+		events:   events,
+		executor: NewCrawlerExecutor(),
+		health:   NewHealthMonitor(),
+	}
 }
 
 // RefreshAllPlatforms 刷新所有平台的比赛数据
@@ -30,41 +60,10 @@ func (s *CrawlerService) RefreshAllPlatforms(ctx context.Context) (map[string]*R
 		go func(crawlerName string, c Crawler) {
 			defer wg.Done()
 
-			startTime := time.Now()
-			result := &RefreshResult{
-				Platform: crawlerName,
-				StartTime: startTime,
-			}
-
-			defer func() {
-				result.Duration = time.Since(startTime).Milliseconds()
-				mu.Lock()
-				results[crawlerName] = result
-				mu.Unlock()
-				
-				// 记录刷新日志
-				s.logRefreshResult(result)
-			}()
-
-			contests, err := c.Crawl(ctx)
-			if err != nil {
-				result.Status = "failed"
-				result.Message = err.Error()
-				return
-			}
-
-			// 保存到数据库
-			newCount, updatedCount, err := s.saveContests(ctx, contests, crawlerName)
-			if err != nil {
-				result.Status = "failed"
-				result.Message = err.Error()
-				return
-			}
-
-			result.Status = "success"
-			result.NewCount = newCount
-			result.UpdatedCount = updatedCount
-			result.Message = fmt.Sprintf("成功获取%d场比赛，新增%d场，更新%d场", len(contests), newCount, updatedCount)
+			result := s.refreshPlatform(ctx, crawlerName, c)
+			mu.Lock()
+			results[crawlerName] = result
+			mu.Unlock()
 		}(name, crawler)
 	}
 
@@ -79,100 +78,427 @@ func (s *CrawlerService) RefreshSinglePlatform(ctx context.Context, platform str
 		return nil, fmt.Errorf("crawler for platform %s not found", platform)
 	}
 
+	result := s.refreshPlatform(ctx, crawler.Name(), crawler)
+	if result.Status == "failed" {
+		return result, errors.New(result.Message)
+	}
+	return result, nil
+}
+
+// refreshPlatform 爬取单个平台并保存结果，同时推送刷新进度事件
+// 同一平台在所有实例间同一时刻只允许一个刷新，锁被占用时直接跳过
+func (s *CrawlerService) refreshPlatform(ctx context.Context, platform string, crawler Crawler) *RefreshResult {
+	ctx, span := tracer.Start(ctx, "crawler.refresh", trace.WithAttributes(attribute.String("platform", platform)))
+	defer span.End()
+
 	startTime := time.Now()
 	result := &RefreshResult{
 		Platform:  platform,
 		StartTime: startTime,
 	}
 
+	ctx, stats := withParseStats(ctx)
 	defer func() {
+		// 锁被其他任务持有时没有实际刷新，不记录结果
+		if result.Status == "skipped" {
+			return
+		}
+		// 刷新因停机或超时被取消、或获取锁失败时，仍需记录本次结果
+		ctx := context.WithoutCancel(ctx)
 		result.Duration = time.Since(startTime).Milliseconds()
-		s.logRefreshResult(result)
+		s.health.Record(ctx, result, result.FetchedCount, stats)
+		s.logRefreshResult(ctx, result)
+		recordRefreshMetrics(result)
+		span.SetAttributes(
+			attribute.String("refresh.status", result.Status),
+			attribute.Int("refresh.new_count", result.NewCount),
+			attribute.Int("refresh.updated_count", result.UpdatedCount),
+		)
+		if result.Status == "failed" {
+			span.SetStatus(codes.Error, result.Message)
+		}
+		log.InfoContext(ctx, "Platform refreshed",
+			"platform", platform,
+			"status", result.Status,
+			"attempts", result.Attempts,
+			"breaker_state", result.BreakerState,
+			"duration_ms", result.Duration,
+		)
 	}()
 
-	contests, err := crawler.Crawl(ctx)
+	lock := redisclient.NewLock(refreshLockKeyPrefix+platform, refreshLockTTL)
+	acquired, err := lock.Acquire(ctx)
 	if err != nil {
-		result.Status = "failed"
-		result.Message = err.Error()
-		return result, err
+		s.failRefresh(ctx, result, fmt.Errorf("failed to acquire refresh lock: %w", err))
+		return result
+	}
+	if !acquired {
+		result.Status = "skipped"
+		result.Message = "该平台正在由其他任务刷新"
+		return result
 	}
+	defer func() {
+		if err := lock.Release(context.WithoutCancel(ctx)); err != nil {
+			log.WarnContext(ctx, "Failed to release refresh lock", "platform", platform, "error", err)
+		}
+	}()
+
+	// 刷新耗时可能超过锁的有效期，后台持续续期直到刷新结束；
+	// 锁一旦丢失立即取消爬取和保存，避免与获得锁的其他实例同时写入
+	crawlCtx, cancelCrawl := context.WithCancelCause(ctx)
+	defer cancelCrawl(nil)
+	lost := lock.KeepAlive(crawlCtx)
+	go func() {
+		select {
+		case <-lost:
+			cancelCrawl(errRefreshLockLost)
+		case <-crawlCtx.Done():
+		}
+	}()
+
+	s.events.Publish(ctx, EventRefreshStarted, platform, nil)
 
-	newCount, updatedCount, err := s.saveContests(ctx, contests, platform)
+	contests, attempts, err := s.executor.Execute(crawlCtx, platform, crawler)
+	result.Attempts = attempts
+	result.BreakerState = s.executor.Breaker(platform).Status().State
+	if err == nil {
+		err = lockLost(crawlCtx)
+	}
 	if err != nil {
-		result.Status = "failed"
-		result.Message = err.Error()
-		return result, err
+		s.failRefresh(ctx, result, refreshError(crawlCtx, err))
+		return result
+	}
+	s.events.Publish(ctx, EventRefreshFetched, platform, map[string]any{
+		"count": len(contests),
+	})
+
+	// 保存到数据库
+	newCount, updatedCount, err := s.saveContests(crawlCtx, contests, platform)
+	if err != nil {
+		s.failRefresh(ctx, result, refreshError(crawlCtx, err))
+		return result
 	}
 
 	result.Status = "success"
+	result.FetchedCount = len(contests)
 	result.NewCount = newCount
 	result.UpdatedCount = updatedCount
 	result.Message = fmt.Sprintf("成功获取%d场比赛，新增%d场，更新%d场", len(contests), newCount, updatedCount)
+	s.events.Publish(ctx, EventRefreshSaved, platform, map[string]any{
+		"count":         len(contests),
+		"new_count":     newCount,
+		"updated_count": updatedCount,
+	})
 
-	return result, nil
+	return result
+}
+
+// lockLost 刷新锁已丢失时返回 errRefreshLockLost
+func lockLost(ctx context.Context) error {
+	if errors.Is(context.Cause(ctx), errRefreshLockLost) {
+		return errRefreshLockLost
+	}
+	return nil
+}
+
+// refreshError 刷新锁丢失导致的失败统一报告为锁丢失，而不是底层的 context canceled
+func refreshError(ctx context.Context, err error) error {
+	if lost := lockLost(ctx); lost != nil {
+		return fmt.Errorf("%w: %w", lost, err)
+	}
+	return err
 }
 
-// saveContests 保存比赛数据到数据库
+// failRefresh 标记刷新失败并推送失败事件
+func (s *CrawlerService) failRefresh(ctx context.Context, result *RefreshResult, err error) {
+	result.Status = "failed"
+	result.Message = err.Error()
+	s.events.Publish(ctx, EventRefreshFailed, result.Platform, map[string]any{
+		"message": result.Message,
+	})
+}
+
+// saveContests 保存比赛数据到数据库，返回新增和内容发生变化的比赛数量
+// 按 (platform, source_id) 批量 upsert，写入前查询已有记录以区分新增和更新，并据此推送比赛变更事件
 func (s *CrawlerService) saveContests(ctx context.Context, contests []*model.Contest, platform string) (int, int, error) {
+	ctx, span := tracer.Start(ctx, "crawler.save_contests", trace.WithAttributes(
+		attribute.String("platform", platform),
+		attribute.Int("contests", len(contests)),
+	))
+	defer span.End()
+
+	now := time.Now()
+	contests = dedupeContests(contests, platform, now)
+	if len(contests) == 0 {
+		return 0, 0, nil
+	}
+	sourceIDs := make([]string, len(contests))
+	for i, contest := range contests {
+		sourceIDs[i] = contest.SourceID
+	}
+
 	var newCount, updatedCount int
+	var pending []func()
+	var changed []model.Contest
 
-	err := database.DB.Transaction(func(tx *gorm.DB) error {
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 已被管理员删除的比赛也会命中唯一索引，upsert 只更新字段而不恢复，因此这里同样要查出来
+		var existingContests []model.Contest
+		if err := tx.Unscoped().
+			Where("platform = ? AND source_id IN ?", platform, sourceIDs).
+			Find(&existingContests).Error; err != nil {
+			return err
+		}
+		existing := make(map[string]*model.Contest, len(existingContests))
+		for i := range existingContests {
+			existing[existingContests[i].SourceID] = &existingContests[i]
+		}
+		// 没有变化的比赛沿用原来的更新时间，增量同步只返回真正变化的比赛
 		for _, contest := range contests {
-			contest.Platform = platform
+			if old, ok := existing[contest.SourceID]; ok && !contestChanged(old, contest) && old.Status == contest.Status {
+				contest.UpdatedAt = old.UpdatedAt
+			}
+		}
+
+		if err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "platform"}, {Name: "source_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"name", "start_time", "end_time", "duration_seconds",
+				"contest_url", "status", "last_updated", "search_text", "updated_at",
+			}),
+		}).CreateInBatches(contests, upsertBatchSize).Error; err != nil {
+			return err
+		}
+
+		// MySQL 的 ON DUPLICATE KEY UPDATE 无法回填已存在记录的主键，重新查询以获得准确的ID
+		var saved []model.Contest
+		if err := tx.Where("platform = ? AND source_id IN ?", platform, sourceIDs).
+			Find(&saved).Error; err != nil {
+			return err
+		}
 
-			var existingContest model.Contest
-			result := tx.Where("source_id = ?", contest.SourceID).First(&existingContest)
+		for i := range saved {
+			contest := &saved[i]
+			dto := eventContestDto(contest)
 
-			if result.Error == gorm.ErrRecordNotFound {
-				// 新比赛
-				if err := tx.Create(contest).Error; err != nil {
-					return err
-				}
+			old, ok := existing[contest.SourceID]
+			if !ok {
 				newCount++
-			} else if result.Error != nil {
-				return result.Error
-			} else {
-				// 更新现有比赛
-				existingContest.Name = contest.Name
-				existingContest.StartTime = contest.StartTime
-				existingContest.EndTime = contest.EndTime
-				existingContest.DurationSeconds = contest.DurationSeconds
-				existingContest.ContestURL = contest.ContestURL
-				existingContest.Status = contest.Status
-				existingContest.LastUpdated = contest.LastUpdated
-
-				if err := tx.Save(&existingContest).Error; err != nil {
-					return err
-				}
+				changed = append(changed, *contest)
+				pending = append(pending, func() {
+					s.events.Publish(ctx, EventContestCreated, platform, dto)
+				})
+				continue
+			}
+
+			if contestChanged(old, contest) || old.Status != contest.Status {
 				updatedCount++
+				changed = append(changed, *contest)
+			}
+			if contestChanged(old, contest) {
+				pending = append(pending, func() {
+					s.events.Publish(ctx, EventContestUpdated, platform, dto)
+				})
+			}
+			if oldStatus := old.StatusAt(now); oldStatus != dto.Status {
+				pending = append(pending, func() {
+					s.publishStatusChange(ctx, platform, dto, oldStatus)
+				})
 			}
 		}
 		return nil
 	})
 
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return 0, 0, err
 	}
 
+	// 事务提交成功后再使缓存失效并推送比赛变更事件，此时即使刷新被取消也要执行完
+	ctx = context.WithoutCancel(ctx)
+	invalidateContests(ctx, changed)
+	for _, publish := range pending {
+		publish()
+	}
+
 	return newCount, updatedCount, nil
 }
 
+// dedupeContests 设置比赛所属平台、当前状态和搜索文本，并按 source_id 去重（保留最后一条）
+// 同一批 upsert 中出现重复的键时，PostgreSQL 会直接报错
+func dedupeContests(contests []*model.Contest, platform string, now time.Time) []*model.Contest {
+	index := make(map[string]int, len(contests))
+	result := make([]*model.Contest, 0, len(contests))
+	for _, contest := range contests {
+		contest.Platform = platform
+		// 以比赛时间为准，不依赖各爬虫自行判断的状态
+		contest.Status = contest.StatusAt(now)
+		contest.SearchText = search.Text(contest.Name)
+		// 统一以本地时区写入，SQLite 按字符串比较时间，时区不一致时范围查询会出错
+		contest.StartTime = contest.StartTime.In(time.Local)
+		contest.EndTime = contest.EndTime.In(time.Local)
+		if i, ok := index[contest.SourceID]; ok {
+			result[i] = contest
+			continue
+		}
+		index[contest.SourceID] = len(result)
+		result = append(result, contest)
+	}
+	return result
+}
+
+// contestChanged 判断爬取到的比赛信息与已有记录相比是否有变化（不含状态）
+func contestChanged(existing, crawled *model.Contest) bool {
+	return existing.Name != crawled.Name ||
+		!existing.StartTime.Equal(crawled.StartTime) ||
+		!existing.EndTime.Equal(crawled.EndTime) ||
+		existing.DurationSeconds != crawled.DurationSeconds ||
+		existing.ContestURL != crawled.ContestURL
+}
+
+// eventContestDto 事件中的比赛数据，事件广播给所有订阅者，比赛时间统一使用默认时区，平台名称使用默认语言
+func eventContestDto(contest *model.Contest) model.ContestDto {
+	dto := contest.ToDto()
+	dto.PlatformName = platform.DisplayName(contest.Platform, i18n.Default)
+	dto.In(timezone.Default())
+	return dto
+}
+
+// statusChange 构造比赛状态变更事件的数据
+func statusChange(dto model.ContestDto, oldStatus string) map[string]any {
+	return map[string]any{
+		"id":         dto.ID,
+		"name":       dto.Name,
+		"old_status": oldStatus,
+		"new_status": dto.Status,
+		"start_time": dto.StartTime,
+		"end_time":   dto.EndTime,
+	}
+}
+
+// UpdateContestStatus 将已到开始或结束时间的比赛更新为对应状态，并为状态发生变化的比赛推送事件
+// 每次只更新状态与时间不一致的行，正常情况下只涉及刚开始或刚结束的少量比赛
+func (s *CrawlerService) UpdateContestStatus(ctx context.Context) error {
+	now := time.Now()
+
+	transitions := []struct {
+		status string
+		where  string
+		args   []any
+	}{
+		// 更新进行中的比赛
+		{model.ContestStatusRunning, "start_time <= ? AND end_time >= ?", []any{now, now}},
+		// 更新已结束的比赛
+		{model.ContestStatusFinished, "end_time < ?", []any{now}},
+		// 更新即将开始的比赛（比赛延期时会从其他状态变回 upcoming）
+		{model.ContestStatusUpcoming, "start_time > ?", []any{now}},
+	}
+
+	for _, t := range transitions {
+		var changed []model.Contest
+		if err := database.DB.WithContext(ctx).
+			Where(t.where, t.args...).
+			Where("status <> ?", t.status).
+			Find(&changed).Error; err != nil {
+			return err
+		}
+		if len(changed) == 0 {
+			continue
+		}
+
+		ids := make([]uint, 0, len(changed))
+		for _, contest := range changed {
+			ids = append(ids, contest.ID)
+		}
+		if err := database.DB.WithContext(ctx).Model(&model.Contest{}).
+			Where("id IN ?", ids).
+			Update("status", t.status).Error; err != nil {
+			return err
+		}
+
+		invalidateContests(ctx, changed)
+		for _, contest := range changed {
+			s.publishStatusChange(ctx, contest.Platform, eventContestDto(&contest), contest.Status)
+		}
+		log.InfoContext(ctx, "Contest status updated", "status", t.status, "count", len(changed))
+	}
+
+	return nil
+}
+
+// publishStatusChange 推送比赛状态变更事件，比赛开始和结束时额外推送对应事件
+func (s *CrawlerService) publishStatusChange(ctx context.Context, platform string, dto model.ContestDto, oldStatus string) {
+	s.events.Publish(ctx, EventContestStatusChanged, platform, statusChange(dto, oldStatus))
+
+	switch dto.Status {
+	case model.ContestStatusRunning:
+		s.events.Publish(ctx, EventContestStarted, platform, dto)
+	case model.ContestStatusFinished:
+		s.events.Publish(ctx, EventContestFinished, platform, dto)
+	}
+}
+
+// NextStatusTransition 获取 after 之后最近一场比赛开始或结束的时间，没有时返回零值
+func (s *CrawlerService) NextStatusTransition(ctx context.Context, after time.Time) (time.Time, error) {
+	var next time.Time
+	for _, column := range []string{"start_time", "end_time"} {
+		var times []time.Time
+		if err := database.DB.WithContext(ctx).Model(&model.Contest{}).
+			Where(column+" > ?", after).
+			Order(column+" ASC").
+			Limit(1).
+			Pluck(column, &times).Error; err != nil {
+			return time.Time{}, err
+		}
+		if len(times) > 0 && (next.IsZero() || times[0].Before(next)) {
+			next = times[0]
+		}
+	}
+	return next, nil
+}
+
+// recordRefreshMetrics 记录刷新相关的监控指标
+func recordRefreshMetrics(result *RefreshResult) {
+	metrics.CrawlerRefreshTotal.WithLabelValues(result.Platform, result.Status).Inc()
+	metrics.CrawlerRefreshDuration.WithLabelValues(result.Platform, result.Status).Observe(float64(result.Duration) / 1000)
+	if result.Status == "success" {
+		metrics.CrawlerContests.WithLabelValues(result.Platform).Set(float64(result.FetchedCount))
+		metrics.CrawlerContestsSaved.WithLabelValues(result.Platform, "new").Add(float64(result.NewCount))
+		metrics.CrawlerContestsSaved.WithLabelValues(result.Platform, "updated").Add(float64(result.UpdatedCount))
+	}
+}
+
 // logRefreshResult 记录刷新结果到数据库
-func (s *CrawlerService) logRefreshResult(result *RefreshResult) {
-	log := &model.ContestRefreshLog{
-		Platform:    result.Platform,
-		Status:      result.Status,
-		Message:     result.Message,
-		NewCount:    result.NewCount,
+func (s *CrawlerService) logRefreshResult(ctx context.Context, result *RefreshResult) {
+	refreshLog := &model.ContestRefreshLog{
+		Platform:     result.Platform,
+		Status:       result.Status,
+		Message:      result.Message,
+		FetchedCount: result.FetchedCount,
+		NewCount:     result.NewCount,
 		UpdatedCount: result.UpdatedCount,
-		Duration:    result.Duration,
+		Duration:     result.Duration,
+		Attempts:     result.Attempts,
+		BreakerState: string(result.BreakerState),
+		RequestID:    logger.RequestID(ctx),
 	}
 
-	if err := database.DB.Create(log).Error; err != nil {
-		fmt.Printf("Failed to log refresh result: %v\n", err)
+	if err := database.DB.WithContext(ctx).Create(refreshLog).Error; err != nil {
+		log.ErrorContext(ctx, "Failed to log refresh result", "platform", result.Platform, "error", err)
 	}
 }
 
+// BreakerStatuses 获取各平台熔断器状态
+func (s *CrawlerService) BreakerStatuses() map[string]BreakerStatus {
+	return s.executor.BreakerStatuses()
+}
+
+// Health 获取爬虫健康监控实例
+func (s *CrawlerService) Health() *HealthMonitor {
+	return s.health
+}
+
 // GetRecentRefreshLogs 获取最近的刷新日志
 func (s *CrawlerService) GetRecentRefreshLogs(limit int) ([]model.ContestRefreshLog, error) {
 	var logs []model.ContestRefreshLog
@@ -181,11 +507,17 @@ func (s *CrawlerService) GetRecentRefreshLogs(limit int) ([]model.ContestRefresh
 }
 
 type RefreshResult struct {
-	Platform     string
-	Status       string
-	Message      string
-	NewCount     int
-	UpdatedCount int
-	Duration     int64
-	StartTime    time.Time
-}
\ No newline at end of file
+	Platform     string `json:"platform"`
+	Status       string `json:"status"`
+	Message      string `json:"message"`
+	FetchedCount int    `json:"fetched_count"`
+	NewCount     int    `json:"new_count"`
+	// UpdatedCount 已存在且内容发生变化的比赛数量，未变化的比赛不计入
+	UpdatedCount int       `json:"updated_count"`
+	Duration     int64     `json:"duration"`
+	StartTime    time.Time `json:"start_time"`
+	// Attempts 实际尝试爬取的次数（含重试）
+	Attempts int `json:"attempts"`
+	// BreakerState 刷新结束时该平台熔断器的状态
+	BreakerState BreakerState `json:"breaker_state,omitempty"`
+}
@@ -0,0 +1,148 @@
+package crawler
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"nicccce-acm-calendar-api/internal/global/logger"
+	"nicccce-acm-calendar-api/tools"
+)
+
+const (
+	// statusMaxWait 两次状态同步的最长间隔，用于兜底主节点切换、其他实例写入等未收到通知的情况
+	statusMaxWait = time.Minute
+	// statusSlack 在开始/结束时刻之后稍作延迟再更新，保证按时间判断时已越过该时刻
+	statusSlack = 100 * time.Millisecond
+)
+
+// StatusTracker 在比赛开始和结束的时刻更新比赛状态
+// 每次同步后查询下一场比赛开始或结束的时间并等待到该时刻，只更新到期的比赛；
+// 比赛数据刷新后会被唤醒重新计算等待时间。多实例部署时只有主节点执行更新
+type StatusTracker struct {
+	service   *CrawlerService
+	scheduler *Scheduler
+	events    *EventBroker
+
+	// next 下一次状态变化的时间（UnixMilli），0 表示暂无
+	next atomic.Int64
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewStatusTracker(service *CrawlerService, scheduler *Scheduler, events *EventBroker) *StatusTracker {
+	return &StatusTracker{
+		service:   service,
+		scheduler: scheduler,
+		events:    events,
+	}
+}
+
+// Start 启动状态同步协程，启动时立即同步一次
+func (t *StatusTracker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+
+	// 比赛数据刷新完成后重新计算下一次状态变化的时间
+	sub := t.events.Subscribe()
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		defer t.events.Unsubscribe(sub)
+
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+			case event, ok := <-sub:
+				if !ok {
+					// 事件广播已关闭，之后只按时间等待
+					sub = nil
+					continue
+				}
+				if event.Type != EventRefreshSaved {
+					continue
+				}
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+			}
+			timer.Reset(t.sync(ctx))
+		}
+	}()
+}
+
+// Stop 停止状态同步协程，ctx 到期时不再等待
+func (t *StatusTracker) Stop(ctx context.Context) {
+	if t.cancel == nil {
+		return
+	}
+	t.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Warn("Status tracker did not stop in time")
+	}
+}
+
+// Next 下一次比赛状态变化的时间，暂无时返回 nil
+func (t *StatusTracker) Next() *time.Time {
+	ms := t.next.Load()
+	if ms == 0 {
+		return nil
+	}
+	next := time.UnixMilli(ms)
+	return &next
+}
+
+// sync 更新到期比赛的状态，返回距下一次状态变化的等待时间
+func (t *StatusTracker) sync(ctx context.Context) time.Duration {
+	if !t.scheduler.IsLeader() {
+		// 启动时可能尚未完成选举，按续约间隔检查，尽快接管
+		t.next.Store(0)
+		return leaderRenewInterval
+	}
+
+	ctx = logger.WithRequestID(ctx, "status-"+tools.RandString(8))
+	now := time.Now()
+	if err := t.service.UpdateContestStatus(ctx); err != nil {
+		log.ErrorContext(ctx, "Failed to update contest status", "error", err)
+		return statusMaxWait
+	}
+
+	next, err := t.service.NextStatusTransition(ctx, now)
+	if err != nil {
+		log.ErrorContext(ctx, "Failed to get next status transition", "error", err)
+		return statusMaxWait
+	}
+	if next.IsZero() {
+		t.next.Store(0)
+		return statusMaxWait
+	}
+	t.next.Store(next.UnixMilli())
+
+	wait := time.Until(next) + statusSlack
+	if wait > statusMaxWait {
+		wait = statusMaxWait
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait
+}
@@ -1,15 +1,22 @@
 package module
 
 import (
+	"context"
 	"github.com/gin-gonic/gin"
+	"nicccce-acm-calendar-api/internal/module/apikey"
 	"nicccce-acm-calendar-api/internal/module/crawler"
 	"nicccce-acm-calendar-api/internal/module/ping"
+	"nicccce-acm-calendar-api/internal/module/user"
 )
 
 type Module interface {
 	GetName() string
 	Init()
 	InitRouter(r *gin.RouterGroup)
+	// Start 在路由注册完成、开始监听前调用，用于启动后台任务
+	Start()
+	// Stop 在停机时按注册的逆序调用，应在 ctx 到期前释放资源并等待后台任务结束
+	Stop(ctx context.Context)
 }
 
 var Modules []Module
@@ -22,6 +29,8 @@ func init() {
 	// Register your module here
 	registerModule([]Module{
 		&ping.ModulePing{},
+		&apikey.ModuleAPIKey{},
 		&crawler.ModuleCrawler{},
+		&user.ModuleUser{},
 	})
 }
@@ -0,0 +1,36 @@
+package ping
+
+import (
+	"net/http"
+
+	"nicccce-acm-calendar-api/internal/global/health"
+	"nicccce-acm-calendar-api/internal/global/openapi"
+)
+
+const tagHealth = "health"
+
+// Docs 健康检查接口的文档，这些接口不使用统一的响应结构
+func (p *ModulePing) Docs() []openapi.Route {
+	return []openapi.Route{
+		{
+			Method: http.MethodGet, Path: "/ping", Tag: tagHealth, Raw: true,
+			Summary: "连通性检查",
+			Data: struct {
+				Message string `json:"message"`
+				Version string `json:"version"`
+			}{},
+		},
+		{
+			Method: http.MethodGet, Path: "/healthz", Tag: tagHealth, Raw: true,
+			Summary:     "存活探针",
+			Description: "进程能处理请求即返回 200，不检查数据库等依赖",
+			Data:        health.Liveness{},
+		},
+		{
+			Method: http.MethodGet, Path: "/readyz", Tag: tagHealth, Raw: true,
+			Summary:     "就绪探针",
+			Description: "关键组件（数据库、Redis）不可用或服务开始停机时返回 503，响应体相同",
+			Data:        health.Report{}, Errors: []int{http.StatusServiceUnavailable},
+		},
+	}
+}
@@ -1,8 +1,12 @@
 package ping
 
 import (
-	"nicccce-acm-calendar-api/internal/global/logger"
+	"context"
 	"log/slog"
+	"nicccce-acm-calendar-api/internal/global/database"
+	"nicccce-acm-calendar-api/internal/global/health"
+	"nicccce-acm-calendar-api/internal/global/logger"
+	"nicccce-acm-calendar-api/internal/global/redis"
 )
 
 var log *slog.Logger
@@ -15,4 +19,29 @@ func (p *ModulePing) GetName() string {
 
 func (p *ModulePing) Init() {
 	log = logger.New("Ping")
+
+	// 数据库和 Redis 不可用时服务无法正常工作，注册为关键组件
+	health.Register("database", true, func(ctx context.Context) (any, error) {
+		sqlDB, err := database.DB.DB()
+		if err != nil {
+			return nil, err
+		}
+		if err := sqlDB.PingContext(ctx); err != nil {
+			return nil, err
+		}
+		stats := sqlDB.Stats()
+		return map[string]any{
+			"open_connections": stats.OpenConnections,
+			"in_use":           stats.InUse,
+		}, nil
+	})
+	if redis.Enabled() {
+		health.Register("redis", true, func(ctx context.Context) (any, error) {
+			return nil, redis.RedisClient.Ping(ctx).Err()
+		})
+	}
 }
+
+func (p *ModulePing) Start() {}
+
+func (p *ModulePing) Stop(ctx context.Context) {}
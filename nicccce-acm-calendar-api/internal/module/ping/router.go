@@ -1,6 +1,9 @@
 package ping
 
 import (
+	"net/http"
+	"nicccce-acm-calendar-api/internal/global/health"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -11,4 +14,20 @@ func (p *ModulePing) InitRouter(r *gin.RouterGroup) {
 			"version": "v1",
 		})
 	})
+
+	// 存活探针：进程能处理请求即返回 200，不检查依赖，依赖故障不应导致容器被重启
+	r.GET("/healthz", func(c *gin.Context) {
+		c.JSON(http.StatusOK, health.Alive())
+	})
+
+	// 就绪探针：关键组件（数据库、Redis）不可用或服务开始停机时返回 503，使负载均衡摘除该实例
+	r.GET("/readyz", func(c *gin.Context) {
+		report := health.Ready(c.Request.Context())
+		if !report.Ready() {
+			log.WarnContext(c.Request.Context(), "Readiness check failed", "status", report.Status, "shutting_down", report.ShuttingDown)
+			c.JSON(http.StatusServiceUnavailable, report)
+			return
+		}
+		c.JSON(http.StatusOK, report)
+	})
 }
@@ -0,0 +1,108 @@
+package user
+
+import (
+	"errors"
+
+	"nicccce-acm-calendar-api/internal/global/cache"
+	"nicccce-acm-calendar-api/internal/global/database"
+	"nicccce-acm-calendar-api/internal/global/jwt"
+	"nicccce-acm-calendar-api/internal/global/response"
+	"nicccce-acm-calendar-api/internal/global/timezone"
+	"nicccce-acm-calendar-api/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// updatePreferencesRequest 修改偏好设置的请求体
+type updatePreferencesRequest struct {
+	// Timezone IANA 时区名或 UTC 偏移，为空表示使用服务端默认时区
+	Timezone string `json:"timezone" binding:"max=64"`
+}
+
+// userTag 用户偏好设置的缓存标签，修改后使其失效
+func userTag(studentID string) string {
+	return "user:" + studentID
+}
+
+// GetPreferences 获取当前用户的偏好设置，未设置过时返回空的设置
+func (m *ModuleUser) GetPreferences(c *gin.Context) {
+	pref, err := loadPreference(c, requestStudentID(c))
+	if err != nil {
+		response.Fail(c, response.ErrDatabase.WithOrigin(err))
+		return
+	}
+	response.Success(c, pref.ToDto())
+}
+
+// UpdatePreferences 修改当前用户的偏好设置，时区保存为规范化后的名称
+func (m *ModuleUser) UpdatePreferences(c *gin.Context) {
+	var req updatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, response.ErrInvalidRequest.WithTips(err.Error()))
+		return
+	}
+
+	pref := model.UserPreference{StudentID: requestStudentID(c)}
+	if req.Timezone != "" {
+		loc, err := timezone.Load(req.Timezone)
+		if err != nil {
+			response.Fail(c, response.ErrInvalidRequest.WithTips("invalid timezone: "+req.Timezone))
+			return
+		}
+		pref.Timezone = loc.String()
+	}
+
+	ctx := c.Request.Context()
+	err := database.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "student_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"timezone", "updated_at"}),
+	}).Create(&pref).Error
+	if err != nil {
+		response.Fail(c, response.ErrDatabase.WithOrigin(err))
+		return
+	}
+	cache.Invalidate(ctx, userTag(pref.StudentID))
+	log.InfoContext(ctx, "User preferences updated", "student_id", pref.StudentID, "timezone", pref.Timezone)
+
+	response.Success(c, pref.ToDto())
+}
+
+// preferredTimezone 请求用户设置的默认时区，匿名请求、未设置或查询失败时返回空字符串
+func preferredTimezone(c *gin.Context) string {
+	studentID := requestStudentID(c)
+	if studentID == "" {
+		return ""
+	}
+	pref, err := loadPreference(c, studentID)
+	if err != nil {
+		log.WarnContext(c.Request.Context(), "Failed to load user preferences", "student_id", studentID, "error", err)
+		return ""
+	}
+	return pref.Timezone
+}
+
+// loadPreference 查询用户的偏好设置，结果按用户缓存
+func loadPreference(c *gin.Context, studentID string) (model.UserPreference, error) {
+	pref, _, err := cache.Load(c.Request.Context(), "user/preferences?student_id="+studentID, []string{userTag(studentID)},
+		func() (model.UserPreference, error) {
+			pref := model.UserPreference{StudentID: studentID}
+			err := database.DB.WithContext(c.Request.Context()).Where("student_id = ?", studentID).First(&pref).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pref, nil
+			}
+			return pref, err
+		})
+	return pref, err
+}
+
+// requestStudentID 请求用户的学号，需在 Auth 或 Identify 之后调用，匿名请求返回空字符串
+func requestStudentID(c *gin.Context) string {
+	if v, ok := c.Get("payload"); ok {
+		if claims, ok := v.(*jwt.Claims); ok {
+			return claims.StudentID
+		}
+	}
+	return ""
+}
@@ -0,0 +1,28 @@
+package user
+
+import (
+	"net/http"
+
+	"nicccce-acm-calendar-api/internal/global/openapi"
+	"nicccce-acm-calendar-api/internal/model"
+)
+
+const tagUsers = "users"
+
+// Docs 用户接口的文档
+func (m *ModuleUser) Docs() []openapi.Route {
+	return []openapi.Route{
+		{
+			Method: http.MethodGet, Path: "/users/me/preferences", Tag: tagUsers, Auth: true,
+			Summary: "获取当前用户的偏好设置",
+			Data:    model.UserPreferenceDto{},
+		},
+		{
+			Method: http.MethodPut, Path: "/users/me/preferences", Tag: tagUsers, Auth: true,
+			Summary:     "修改当前用户的偏好设置",
+			Description: "timezone 为 IANA 时区名或 UTC 偏移，保存后作为未传入 tz 参数和 X-Timezone 请求头时的默认时区；为空表示使用服务端默认时区",
+			Body:        updatePreferencesRequest{},
+			Data:        model.UserPreferenceDto{},
+		},
+	}
+}
@@ -0,0 +1,27 @@
+package user
+
+import (
+	"context"
+	"log/slog"
+
+	"nicccce-acm-calendar-api/internal/global/logger"
+	"nicccce-acm-calendar-api/internal/global/timezone"
+)
+
+var log *slog.Logger
+
+type ModuleUser struct{}
+
+func (m *ModuleUser) GetName() string {
+	return "User"
+}
+
+// Init 注册用户设置的默认时区，未指定时区的请求按请求用户的设置输出时间
+func (m *ModuleUser) Init() {
+	log = logger.New("User")
+	timezone.SetPreference(preferredTimezone)
+}
+
+func (m *ModuleUser) Start() {}
+
+func (m *ModuleUser) Stop(ctx context.Context) {}
@@ -0,0 +1,17 @@
+package user
+
+import (
+	"nicccce-acm-calendar-api/internal/global/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (m *ModuleUser) InitRouter(r *gin.RouterGroup) {
+	// 当前用户的偏好设置，需要用户 Token
+	meGroup := r.Group("/users/me")
+	meGroup.Use(middleware.Auth(0))
+	{
+		meGroup.GET("/preferences", m.GetPreferences)
+		meGroup.PUT("/preferences", m.UpdatePreferences)
+	}
+}
@@ -1,8 +1,25 @@
 package main
 
-import "nicccce-acm-calendar-api/cmd/server"
+import (
+	"nicccce-acm-calendar-api/cmd/apikey"
+	"nicccce-acm-calendar-api/cmd/migrate"
+	"nicccce-acm-calendar-api/cmd/server"
+	"os"
+)
 
 func main() {
+	// main migrate [up|down [n]|status]
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		migrate.Run(os.Args[2:])
+		return
+	}
+
+	// main apikey [create <name> [scope...]|list|revoke <id>]
+	if len(os.Args) > 1 && os.Args[1] == "apikey" {
+		apikey.Run(os.Args[2:])
+		return
+	}
+
 	server.Init()
 	server.Run()
 }
@@ -5,6 +5,13 @@ import apiService from '../services/api';
 
 const { Title, Paragraph } = Typography;
 
+// 轮询刷新任务状态的间隔和最长等待时间（毫秒）
+const REFRESH_JOB_POLL_INTERVAL = 2000;
+const REFRESH_JOB_TIMEOUT = 5 * 60 * 1000;
+
+// 平台列表中没有该平台或平台未设置品牌色时使用的颜色
+const DEFAULT_PLATFORM_COLOR = '#6366f1';
+
 const HomePage = () => {
   const [contests, setContests] = useState([]);
   const [filteredContests, setFilteredContests] = useState([]);
@@ -12,6 +19,7 @@ const HomePage = () => {
   const [error, setError] = useState(null);
   const [activeFilter, setActiveFilter] = useState('all');
   const [lastUpdate, setLastUpdate] = useState(null);
+  const [platforms, setPlatforms] = useState([]);
 
   // 获取比赛数据
   const fetchContests = async () => {
@@ -33,13 +41,37 @@ const HomePage = () => {
     }
   };
 
+  // 获取平台列表，失败时平台按比赛数据中的名称和默认颜色显示
+  const fetchPlatforms = async () => {
+    try {
+      const data = await apiService.getPlatforms();
+      setPlatforms(Array.isArray(data) ? data : []);
+    } catch (err) {
+      console.error('获取平台列表失败:', err);
+    }
+  };
+
+  // 等待刷新任务结束，任务在后台执行，需轮询任务状态
+  const waitForRefreshJob = async (job) => {
+    const deadline = Date.now() + REFRESH_JOB_TIMEOUT;
+    while (job && job.status !== 'finished') {
+      if (Date.now() > deadline) {
+        throw new Error('刷新任务超时');
+      }
+      await new Promise(resolve => setTimeout(resolve, REFRESH_JOB_POLL_INTERVAL));
+      job = await apiService.getRefreshJob(job.id);
+    }
+    return job;
+  };
+
   // 刷新数据
   const refreshData = async () => {
     setLoading(true);
     setError(null);
 
     try {
-      await apiService.refreshAllPlatforms();
+      const job = await apiService.refreshAllPlatforms();
+      await waitForRefreshJob(job);
       await fetchContests();
     } catch (err) {
       console.error('刷新数据失败:', err);
@@ -84,33 +116,43 @@ const HomePage = () => {
     }
   };
 
-  // 获取平台颜色
-  const getPlatformColor = (platform) => {
-    switch (platform) {
-      case 'Codeforces': return '#10b981';
-      case 'AtCoder': return '#ef4444';
-      case 'LeetCode': return '#06b6d4';
-      case 'NowCoder':
-      case '牛客': return '#3b82f6';
-      case 'Luogu':
-      case '洛谷': return '#f59e0b';
-      default: return '#6366f1';
-    }
+  // 按标识查找平台列表中的平台
+  const findPlatform = (slug) => platforms.find(p => p.slug === slug);
+
+  // 获取平台颜色，使用平台列表中的品牌色
+  const getPlatformColor = (slug) => {
+    const platform = findPlatform(slug);
+    return (platform && platform.color) || DEFAULT_PLATFORM_COLOR;
   };
 
   // 组件挂载时获取数据
   useEffect(() => {
+    fetchPlatforms();
     fetchContests();
   }, []);
 
-  // 获取所有平台
+  // 获取有比赛的所有平台，按平台列表的展示顺序排列
   const getAllPlatforms = () => {
     // 确保 contests 是数组并且有数据
     if (!Array.isArray(contests) || contests.length === 0) {
       return [];
     }
-    const platforms = [...new Set(contests.map(contest => contest.platform))];
-    return platforms;
+    const slugs = [...new Set(contests.map(contest => contest.platform))];
+    const order = (slug) => {
+      const index = platforms.findIndex(p => p.slug === slug);
+      return index === -1 ? platforms.length : index;
+    };
+    return slugs.sort((a, b) => order(a) - order(b));
+  };
+
+  // 获取平台显示名称，优先使用平台列表中的名称
+  const getPlatformName = (slug) => {
+    const platform = findPlatform(slug);
+    if (platform && platform.name) {
+      return platform.name;
+    }
+    const contest = Array.isArray(contests) && contests.find(c => c.platform === slug);
+    return (contest && contest.platform_name) || slug;
   };
 
   return (
@@ -172,7 +214,7 @@ const HomePage = () => {
                 } : {}
               }
             >
-              {platform}
+              {getPlatformName(platform)}
             </Button>
           ))}
         </div>
@@ -232,7 +274,7 @@ const HomePage = () => {
                     fontWeight: 'bold'
                   }}
                 >
-                  {contest.platform}
+                  {contest.platform_name || contest.platform}
                 </Tag>
 
                 {/* 比赛名称 */}
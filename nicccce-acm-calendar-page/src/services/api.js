@@ -2,7 +2,7 @@ import axios from 'axios';
 
 // 创建axios实例
 const apiClient = axios.create({
-  baseURL: 'https://分形黄昏.nicccce.xyz/api',
+  baseURL: 'https://分形黄昏.nicccce.xyz/api/v1',
   timeout: 10000,
   headers: {
     'Content-Type': 'application/json'
@@ -82,7 +82,18 @@ class ApiService {
     }
   }
 
-  // 刷新所有平台数据
+  // 获取所有平台，包括显示名称和品牌色
+  async getPlatforms() {
+    try {
+      const response = await apiClient.get('/platforms');
+      return response.data;
+    } catch (error) {
+      console.error('获取平台列表失败:', error);
+      throw error;
+    }
+  }
+
+  // 刷新所有平台数据，返回异步刷新任务
   async refreshAllPlatforms() {
     try {
       const response = await apiClient.post('/refresh');
@@ -93,6 +104,17 @@ class ApiService {
     }
   }
 
+  // 查询刷新任务
+  async getRefreshJob(id) {
+    try {
+      const response = await apiClient.get(`/refresh/jobs/${id}`);
+      return response.data;
+    } catch (error) {
+      console.error('查询刷新任务失败:', error);
+      throw error;
+    }
+  }
+
   // 刷新单个平台数据
   async refreshSinglePlatform(platform) {
     try {